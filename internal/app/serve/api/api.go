@@ -1,25 +1,83 @@
 package api
 
 import (
-	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"nt-bootstrap-scraper/pkg/history"
 	"nt-bootstrap-scraper/pkg/nitrotype"
+	"nt-bootstrap-scraper/pkg/snapshot"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/httprate"
-	"github.com/patrickmn/go-cache"
 	"go.uber.org/zap"
 )
 
+// maxBatchRacers is the most usernames accepted by a single racers request.
+const maxBatchRacers = 100
+
+// maxSeasonLevel is the highest target level accepted by a season progress request.
+const maxSeasonLevel = 10000
+
+// snapshotResult is a stored bootstrap file with the snapshot describing it.
+type snapshotResult struct {
+	Snapshot snapshot.Snapshot `json:"snapshot"`
+	Data     json.RawMessage   `json:"data"`
+}
+
+// bootstrapChanges is the diff between two stored bootstrap files.
+type bootstrapChanges struct {
+	From snapshot.Snapshot `json:"from"`
+	To   snapshot.Snapshot `json:"to"`
+	*nitrotype.BootstrapDiff
+}
+
+// defaultHistoryWindow is how far back racer history goes when since is not given.
+const defaultHistoryWindow = 30 * 24 * time.Hour
+
+// racerHistory is the stats recorded for a watched racer.
+type racerHistory struct {
+	Username string               `json:"username"`
+	Since    time.Time            `json:"since"`
+	Until    time.Time            `json:"until"`
+	Points   []history.StatPoint  `json:"points"`
+	Daily    []history.DailyDelta `json:"daily"`
+}
+
+// defaultMoversWindow and defaultMoversLimit are the window and list length of rank movers when not given.
+const (
+	defaultMoversWindow = 24 * time.Hour
+	defaultMoversLimit  = 10
+	maxMoversLimit      = 100
+)
+
+// rankMovement is where a player or team was ranked over time.
+type rankMovement struct {
+	Kind      history.RankKind       `json:"kind"`
+	ID        int                    `json:"id"`
+	Positions []history.RankPosition `json:"positions"`
+}
+
+// racerResult is the outcome of one username in a racers request.
+type racerResult struct {
+	Username string              `json:"username"`
+	Status   int                 `json:"status"`
+	Player   *nitrotype.NTPlayer `json:"player,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
 // NewAPIService sets up the API Service for Raffles
-func NewAPIService(logger *zap.Logger, cacheManager *cache.Cache, corsOptions *cors.Options) http.Handler {
+func NewAPIService(logger *zap.Logger, fetcher nitrotype.Fetcher, leaderboard *nitrotype.LeaderboardResolver, browser *nitrotype.Browser, proxies *nitrotype.ProxyPool, snapshots snapshot.Store, stats history.Store, adminToken string, corsOptions *cors.Options) http.Handler {
 	corsMiddleware := cors.Handler(*corsOptions)
+	imageClient := nitrotype.NewProxyClient(proxies)
 
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
@@ -35,12 +93,11 @@ func NewAPIService(logger *zap.Logger, cacheManager *cache.Cache, corsOptions *c
 		r.Get("/bootstrap", func(w http.ResponseWriter, r *http.Request) {
 			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
 
-			source, err := getBootstrapData(logger, cacheManager)
+			source, err := fetcher.GetBootstrapData(r.Context())
 			if err != nil {
 				log.Error("grabbing bootstrap data from nitro type failed", zap.Error(err))
 
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte("Unable to collect NT Bootstrap Data. Please try again later."))
+				writeScrapeError(w, err, "Unable to collect NT Bootstrap Data. Please try again later.")
 				return
 			}
 
@@ -51,6 +108,179 @@ func NewAPIService(logger *zap.Logger, cacheManager *cache.Cache, corsOptions *c
 				log.Error("exporting bootstrap data from nitro type failed", zap.Error(err))
 			}
 		})
+		r.Get("/bootstrap/schema", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			source, err := fetcher.GetBootstrapData(r.Context())
+			if err != nil {
+				log.Error("grabbing bootstrap data from nitro type failed", zap.Error(err))
+
+				writeScrapeError(w, err, "Unable to collect NT Bootstrap Data. Please try again later.")
+				return
+			}
+			report, err := nitrotype.CheckSchema(*source)
+			if err != nil {
+				log.Error("checking bootstrap schema failed", zap.Error(err))
+
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("Unable to check NT Bootstrap Data schema."))
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(report)
+			if err != nil {
+				log.Error("exporting bootstrap schema report failed", zap.Error(err))
+			}
+		})
+		r.Get("/bootstrap/changes", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			if snapshots == nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("Snapshot store is not in use."))
+				return
+			}
+
+			// to defaults to the latest snapshot and from to the one taken before to
+			query := r.URL.Query()
+			var to, from snapshot.Snapshot
+			var toData, fromData []byte
+			var err error
+			if id := query.Get("to"); id == "" || id == "latest" {
+				to, toData, err = snapshots.Latest()
+			} else {
+				to, toData, err = snapshots.Get(id)
+			}
+			if err == nil {
+				if id := query.Get("from"); id != "" {
+					from, fromData, err = snapshots.Get(id)
+				} else {
+					from, fromData, err = previousSnapshot(snapshots, to.ID)
+				}
+			}
+			if errors.Is(err, snapshot.ErrNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("NT Bootstrap snapshot was not found."))
+				return
+			}
+			if err != nil {
+				log.Error("reading bootstrap snapshots failed", zap.Error(err))
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("Unable to read NT Bootstrap snapshots."))
+				return
+			}
+
+			var fromSource, toSource nitrotype.NTGlobalsLegacy
+			err = json.Unmarshal(fromData, &fromSource)
+			if err == nil {
+				err = json.Unmarshal(toData, &toSource)
+			}
+			var diff *nitrotype.BootstrapDiff
+			if err == nil {
+				diff, err = nitrotype.DiffBootstrap(fromSource, toSource)
+			}
+			if err != nil {
+				log.Error("comparing bootstrap snapshots failed", zap.Error(err))
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("Unable to compare NT Bootstrap snapshots."))
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(bootstrapChanges{From: from, To: to, BootstrapDiff: diff})
+			if err != nil {
+				log.Error("exporting bootstrap changes failed", zap.Error(err))
+			}
+		})
+		r.Get("/globals", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			source, err := nitrotype.GetRequiredGlobals(r.Context(), fetcher, logGlobalsWarning(log))
+			if err != nil {
+				log.Error("grabbing typed bootstrap data from nitro type failed", zap.Error(err))
+
+				writeScrapeError(w, err, "Unable to collect NT Bootstrap Data. Please try again later.")
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(source)
+			if err != nil {
+				log.Error("exporting typed bootstrap data from nitro type failed", zap.Error(err))
+			}
+		})
+		r.Get("/upstream", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"unavailablePages": nitrotype.UnavailablePageCounts(),
+			})
+		})
+		r.Get("/browser", func(w http.ResponseWriter, r *http.Request) {
+			if browser == nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("Shared browser is not in use."))
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(browser.Stats())
+		})
+		r.Get("/proxies", func(w http.ResponseWriter, r *http.Request) {
+			if proxies == nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("Outbound proxies are not in use."))
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(proxies.Stats())
+		})
+		r.Route("/snapshots", func(r chi.Router) {
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if snapshots == nil {
+						w.WriteHeader(http.StatusNotFound)
+						w.Write([]byte("Snapshot store is not in use."))
+						return
+					}
+					next.ServeHTTP(w, r)
+				})
+			})
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+				list, err := snapshots.List()
+				if err != nil {
+					log.Error("listing bootstrap snapshots failed", zap.Error(err))
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte("Unable to list NT Bootstrap snapshots."))
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				err = json.NewEncoder(w).Encode(list)
+				if err != nil {
+					log.Error("exporting bootstrap snapshots failed", zap.Error(err))
+				}
+			})
+			r.Get("/latest", func(w http.ResponseWriter, r *http.Request) {
+				writeSnapshot(w, r, logger, snapshots.Latest)
+			})
+			r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+				id := chi.URLParam(r, "id")
+				writeSnapshot(w, r, logger, func() (snapshot.Snapshot, []byte, error) {
+					return snapshots.Get(id)
+				})
+			})
+		})
 		r.Get("/racer/{username}", func(w http.ResponseWriter, r *http.Request) {
 			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
 
@@ -60,28 +290,627 @@ func NewAPIService(logger *zap.Logger, cacheManager *cache.Cache, corsOptions *c
 				w.Write([]byte("Invalid racer profile request."))
 				return
 			}
+			expand := r.URL.Query().Get("expand")
+			if expand != "" && expand != "catalog" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Invalid racer profile request, expand only supports catalog."))
+				return
+			}
 
-			racer, err := getPlayerData(r.Context(), logger, cacheManager, username)
+			racer, err := fetcher.GetPlayerData(r.Context(), username)
 			if err != nil {
 				log.Error("grabbing player data from nitro type failed", zap.Error(err))
-				if errors.Is(err, nitrotype.ErrPlayerNotFound) {
-					w.WriteHeader(http.StatusNotFound)
-					w.Write([]byte("NT Player was not found."))
+				writeScrapeError(w, err, "Unable to collect NT Player Data. Please try again later.")
+				return
+			}
+
+			var output interface{} = racer
+			if expand == "catalog" {
+				globals, err := nitrotype.GetRequiredGlobals(r.Context(), fetcher, logGlobalsWarning(log), "CARS", "LOOT")
+				if err != nil {
+					log.Error("grabbing typed bootstrap data from nitro type failed", zap.Error(err))
+					writeScrapeError(w, err, "Unable to collect NT Bootstrap Data. Please try again later.")
 					return
 				}
+				output = nitrotype.JoinCatalog(racer, globals)
+			}
 
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(output)
+			if err != nil {
+				log.Error("exporting racer data from nitro type failed", zap.Error(err))
+			}
+		})
+		r.Get("/racer/{username}/achievements", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			username := chi.URLParam(r, "username")
+			if username == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Invalid racer achievements request."))
+				return
+			}
+
+			racer, err := fetcher.GetPlayerData(r.Context(), username)
+			if err != nil {
+				log.Error("grabbing player data from nitro type failed", zap.Error(err))
+				writeScrapeError(w, err, "Unable to collect NT Player Data. Please try again later.")
+				return
+			}
+			globals, err := nitrotype.GetRequiredGlobals(r.Context(), fetcher, logGlobalsWarning(log), "ACHIEVEMENTS")
+			if err != nil {
+				log.Error("grabbing typed bootstrap data from nitro type failed", zap.Error(err))
+				writeScrapeError(w, err, "Unable to collect NT Bootstrap Data. Please try again later.")
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(nitrotype.EvaluateAchievements(racer, globals))
+			if err != nil {
+				log.Error("exporting racer achievements failed", zap.Error(err))
+			}
+		})
+		r.Get("/racer/{username}/history", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			if stats == nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("Racer history is not in use."))
+				return
+			}
+			username, err := history.NormalizeUsername(chi.URLParam(r, "username"))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Invalid racer history request."))
+				return
+			}
+			query := r.URL.Query()
+			until, err := parseTimeParam(query.Get("until"), time.Now())
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Invalid racer history request, until must be a date, RFC 3339 time or unix time."))
+				return
+			}
+			since, err := parseTimeParam(query.Get("since"), until.Add(-defaultHistoryWindow))
+			if err != nil || !since.Before(until) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Invalid racer history request, since must be a date, RFC 3339 time or unix time before until."))
+				return
+			}
+
+			points, err := stats.Range(username, since, until)
+			if err != nil {
+				log.Error("reading racer history failed", zap.Error(err))
 				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte("Unable to collect NT Player Data. Please try again later."))
+				w.Write([]byte("Unable to read NT Racer history."))
 				return
 			}
 
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
-			err = json.NewEncoder(w).Encode(racer)
+			err = json.NewEncoder(w).Encode(racerHistory{
+				Username: username,
+				Since:    since,
+				Until:    until,
+				Points:   points,
+				Daily:    history.DailyDeltas(points),
+			})
 			if err != nil {
-				log.Error("exporting racer data from nitro type failed", zap.Error(err))
+				log.Error("exporting racer history failed", zap.Error(err))
 			}
 		})
+		r.Post("/racers", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			var usernames []string
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&usernames); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Invalid racers request, expected a JSON list of usernames."))
+				return
+			}
+			if len(usernames) == 0 || len(usernames) > maxBatchRacers {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(fmt.Sprintf("Invalid racers request, expected between 1 and %d usernames.", maxBatchRacers)))
+				return
+			}
+			for _, username := range usernames {
+				if username == "" {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("Invalid racers request, usernames cannot be empty."))
+					return
+				}
+			}
+
+			results := nitrotype.GetPlayersData(r.Context(), usernames, nitrotype.PlayersOptions{Fetcher: fetcher})
+
+			output := make([]racerResult, 0, len(results))
+			for _, result := range results {
+				item := racerResult{
+					Username: result.Username,
+					Status:   http.StatusOK,
+					Player:   result.Player,
+				}
+				if result.Err != nil {
+					log.Warn("grabbing player data from nitro type failed", zap.String("username", result.Username), zap.Error(result.Err))
+					item.Status, item.Error = scrapeErrorStatus(result.Err)
+					if item.Status == 0 {
+						item.Status, item.Error = http.StatusInternalServerError, "Unable to collect NT Player Data. Please try again later."
+					}
+				}
+				output = append(output, item)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err := json.NewEncoder(w).Encode(output)
+			if err != nil {
+				log.Error("exporting racers data from nitro type failed", zap.Error(err))
+			}
+		})
+		r.Get("/team/{tag}", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			tag := chi.URLParam(r, "tag")
+			if tag == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Invalid team request."))
+				return
+			}
+
+			team, err := fetcher.GetTeamData(r.Context(), tag)
+			if err != nil {
+				log.Error("grabbing team data from nitro type failed", zap.Error(err))
+				writeScrapeError(w, err, "Unable to collect NT Team Data. Please try again later.")
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(team)
+			if err != nil {
+				log.Error("exporting team data from nitro type failed", zap.Error(err))
+			}
+		})
+		r.Get("/scoreboard/{kind}/{timeframe}", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			kind := nitrotype.ScoreboardKind(chi.URLParam(r, "kind"))
+			timeframe := nitrotype.ScoreboardTimeframe(chi.URLParam(r, "timeframe"))
+			if err := nitrotype.ValidateScoreboard(kind, timeframe); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Invalid scoreboard request, expected /scoreboard/{individual|team}/{daily|weekly|monthly|season}."))
+				return
+			}
+
+			scoreboard, err := fetcher.GetScoreboard(r.Context(), kind, timeframe)
+			if err != nil {
+				log.Error("grabbing scoreboard data from nitro type failed", zap.Error(err))
+				writeScrapeError(w, err, "Unable to collect NT Scoreboard Data. Please try again later.")
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(scoreboard)
+			if err != nil {
+				log.Error("exporting scoreboard data from nitro type failed", zap.Error(err))
+			}
+		})
+		r.Get("/season/progress", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			query := r.URL.Query()
+			xp, err := strconv.ParseInt(query.Get("xp"), 10, 64)
+			if err != nil || xp < 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Invalid season progress request, xp must be zero or more."))
+				return
+			}
+			target := 0
+			if value := query.Get("target"); value != "" {
+				target, err = strconv.Atoi(value)
+				if err != nil || target < 1 || target > maxSeasonLevel {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(fmt.Sprintf("Invalid season progress request, target must be a level between 1 and %d.", maxSeasonLevel)))
+					return
+				}
+			}
+			seasonID := 0
+			if value := query.Get("season"); value != "" {
+				seasonID, err = strconv.Atoi(value)
+				if err != nil || seasonID < 1 {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("Invalid season progress request, season must be a season ID."))
+					return
+				}
+			}
+
+			globals, err := nitrotype.GetRequiredGlobals(r.Context(), fetcher, logGlobalsWarning(log), "ACTIVE_SEASONS", "SEASON_LEVELS")
+			if err != nil {
+				log.Error("grabbing typed bootstrap data from nitro type failed", zap.Error(err))
+				writeScrapeError(w, err, "Unable to collect NT Bootstrap Data. Please try again later.")
+				return
+			}
+			now := time.Now()
+			season, err := globals.Season(seasonID, now)
+			if err != nil {
+				writeScrapeError(w, err, "Unable to find NT Season.")
+				return
+			}
+			progress := nitrotype.CalculateSeasonProgress(globals.SeasonLevels, *season, xp, target, now)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(progress)
+			if err != nil {
+				log.Error("exporting season progress failed", zap.Error(err))
+			}
+		})
+		r.Get("/cash/quote", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			query := r.URL.Query()
+			transfer := nitrotype.CashTransfer{}
+			var err error
+			for _, param := range []struct {
+				name     string
+				value    *int64
+				required bool
+			}{
+				{"amount", &transfer.Amount, true},
+				{"sent", &transfer.SentThisWeek, false},
+				{"created", &transfer.CreatedStamp, false},
+			} {
+				value := query.Get(param.name)
+				if value == "" && !param.required {
+					continue
+				}
+				*param.value, err = strconv.ParseInt(value, 10, 64)
+				if err != nil || *param.value < 0 {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(fmt.Sprintf("Invalid cash quote request, %s must be a positive number.", param.name)))
+					return
+				}
+			}
+			if value := query.Get("level"); value != "" {
+				transfer.SenderLevel, err = strconv.Atoi(value)
+				if err != nil || transfer.SenderLevel < 0 {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("Invalid cash quote request, level must be a positive number."))
+					return
+				}
+			}
+			transfer.Team = query.Get("team") == "true" || query.Get("team") == "1"
+
+			// The sender's profile fills in whatever level and account age were not given
+			if username := query.Get("username"); username != "" {
+				racer, err := fetcher.GetPlayerData(r.Context(), username)
+				if err != nil {
+					log.Error("grabbing player data from nitro type failed", zap.Error(err))
+					writeScrapeError(w, err, "Unable to collect NT Player Data. Please try again later.")
+					return
+				}
+				if query.Get("level") == "" {
+					transfer.SenderLevel = racer.Level
+				}
+				if query.Get("created") == "" {
+					transfer.CreatedStamp = int64(racer.CreatedStamp)
+				}
+			}
+
+			globals, err := nitrotype.GetRequiredGlobals(r.Context(), fetcher, logGlobalsWarning(log), "CASH_SENDING")
+			if err != nil {
+				log.Error("grabbing typed bootstrap data from nitro type failed", zap.Error(err))
+				writeScrapeError(w, err, "Unable to collect NT Bootstrap Data. Please try again later.")
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(globals.CashSending.Quote(transfer, time.Now()))
+			if err != nil {
+				log.Error("exporting cash quote failed", zap.Error(err))
+			}
+		})
+		r.Get("/cars/{id}/image", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			carID, err := strconv.Atoi(chi.URLParam(r, "id"))
+			if err != nil || carID < 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Invalid car image request, id must be a car ID."))
+				return
+			}
+			query := r.URL.Query()
+			size := nitrotype.CarImageLarge
+			if value := query.Get("size"); value != "" {
+				size = nitrotype.CarImageSize(value)
+			}
+			hue := 0
+			if value := query.Get("hue"); value != "" {
+				hue, err = strconv.Atoi(value)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("Invalid car image request, hue must be a number."))
+					return
+				}
+			}
+
+			globals, err := nitrotype.GetRequiredGlobals(r.Context(), fetcher, logGlobalsWarning(log), "CARS", "SITES")
+			if err != nil {
+				log.Error("grabbing typed bootstrap data from nitro type failed", zap.Error(err))
+				writeScrapeError(w, err, "Unable to collect NT Bootstrap Data. Please try again later.")
+				return
+			}
+			imageURL, err := globals.CarImage(carID, size, hue)
+			if err != nil {
+				writeScrapeError(w, err, "Unable to find NT Car image.")
+				return
+			}
+
+			if query.Get("proxy") != "true" && query.Get("proxy") != "1" {
+				http.Redirect(w, r, imageURL, http.StatusFound)
+				return
+			}
+			proxyCarImage(w, r, log, imageClient, imageURL)
+		})
+		r.Get("/shop/current", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			globals, err := nitrotype.GetRequiredGlobals(r.Context(), fetcher, logGlobalsWarning(log), "SHOP", "DEALERSHIP", "CARS", "LOOT")
+			if err != nil {
+				log.Error("grabbing typed bootstrap data from nitro type failed", zap.Error(err))
+				writeScrapeError(w, err, "Unable to collect NT Bootstrap Data. Please try again later.")
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(nitrotype.NewShopTimeline(globals, time.Now()).Current())
+			if err != nil {
+				log.Error("exporting shop timeline failed", zap.Error(err))
+			}
+		})
+		r.Get("/shop/next-rotation", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			globals, err := nitrotype.GetRequiredGlobals(r.Context(), fetcher, logGlobalsWarning(log), "SHOP", "DEALERSHIP", "CARS", "LOOT")
+			if err != nil {
+				log.Error("grabbing typed bootstrap data from nitro type failed", zap.Error(err))
+				writeScrapeError(w, err, "Unable to collect NT Bootstrap Data. Please try again later.")
+				return
+			}
+			next := nitrotype.NewShopTimeline(globals, time.Now()).NextRotation()
+			if next == nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("No NT Shop rotation is scheduled."))
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(next)
+			if err != nil {
+				log.Error("exporting shop rotation failed", zap.Error(err))
+			}
+		})
+		r.Get("/leaderboard/players", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			players, err := leaderboard.Players(r.Context())
+			if err != nil {
+				log.Error("resolving top players failed", zap.Error(err))
+				writeScrapeError(w, err, "Unable to collect NT Leaderboard Data. Please try again later.")
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(players)
+			if err != nil {
+				log.Error("exporting top players failed", zap.Error(err))
+			}
+		})
+		r.Get("/leaderboard/teams", func(w http.ResponseWriter, r *http.Request) {
+			log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+			teams, err := leaderboard.Teams(r.Context())
+			if err != nil {
+				log.Error("resolving top teams failed", zap.Error(err))
+				writeScrapeError(w, err, "Unable to collect NT Leaderboard Data. Please try again later.")
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(w).Encode(teams)
+			if err != nil {
+				log.Error("exporting top teams failed", zap.Error(err))
+			}
+		})
+		r.Route("/ranks/{kind}", func(r chi.Router) {
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if stats == nil {
+						w.WriteHeader(http.StatusNotFound)
+						w.Write([]byte("Rank history is not in use."))
+						return
+					}
+					if err := history.ValidateRankKind(history.RankKind(chi.URLParam(r, "kind"))); err != nil {
+						w.WriteHeader(http.StatusBadRequest)
+						w.Write([]byte("Invalid rank history request, expected /ranks/{players|teams}."))
+						return
+					}
+					next.ServeHTTP(w, r)
+				})
+			})
+			r.Get("/changes", func(w http.ResponseWriter, r *http.Request) {
+				log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+				snapshots, err := stats.LastRanks(history.RankKind(chi.URLParam(r, "kind")), 2)
+				if err != nil {
+					log.Error("reading rank history failed", zap.Error(err))
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte("Unable to read NT Rank history."))
+					return
+				}
+				if len(snapshots) < 2 {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte("Not enough NT Rank history has been recorded yet."))
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				err = json.NewEncoder(w).Encode(history.CompareRanks(snapshots[0], snapshots[1]))
+				if err != nil {
+					log.Error("exporting rank changes failed", zap.Error(err))
+				}
+			})
+			r.Get("/movers", func(w http.ResponseWriter, r *http.Request) {
+				log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+				query := r.URL.Query()
+				until, err := parseTimeParam(query.Get("until"), time.Now())
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("Invalid rank movers request, until must be a date, RFC 3339 time or unix time."))
+					return
+				}
+				since, err := parseTimeParam(query.Get("since"), until.Add(-defaultMoversWindow))
+				if err != nil || !since.Before(until) {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("Invalid rank movers request, since must be a date, RFC 3339 time or unix time before until."))
+					return
+				}
+				limit := defaultMoversLimit
+				if value := query.Get("limit"); value != "" {
+					limit, err = strconv.Atoi(value)
+					if err != nil || limit < 1 || limit > maxMoversLimit {
+						w.WriteHeader(http.StatusBadRequest)
+						w.Write([]byte(fmt.Sprintf("Invalid rank movers request, limit must be between 1 and %d.", maxMoversLimit)))
+						return
+					}
+				}
+
+				snapshots, err := stats.Ranks(history.RankKind(chi.URLParam(r, "kind")), since, until)
+				if err != nil {
+					log.Error("reading rank history failed", zap.Error(err))
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte("Unable to read NT Rank history."))
+					return
+				}
+				if len(snapshots) < 2 {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte("Not enough NT Rank history was recorded in that window."))
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				err = json.NewEncoder(w).Encode(history.BiggestMovers(snapshots[0], snapshots[len(snapshots)-1], limit))
+				if err != nil {
+					log.Error("exporting rank movers failed", zap.Error(err))
+				}
+			})
+			r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+				log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+				kind := history.RankKind(chi.URLParam(r, "kind"))
+				id, err := strconv.Atoi(chi.URLParam(r, "id"))
+				if err != nil || id < 1 {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("Invalid rank history request, id must be a user or team ID."))
+					return
+				}
+				query := r.URL.Query()
+				until, err := parseTimeParam(query.Get("until"), time.Now())
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("Invalid rank history request, until must be a date, RFC 3339 time or unix time."))
+					return
+				}
+				since, err := parseTimeParam(query.Get("since"), until.Add(-defaultHistoryWindow))
+				if err != nil || !since.Before(until) {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("Invalid rank history request, since must be a date, RFC 3339 time or unix time before until."))
+					return
+				}
+
+				snapshots, err := stats.Ranks(kind, since, until)
+				if err != nil {
+					log.Error("reading rank history failed", zap.Error(err))
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte("Unable to read NT Rank history."))
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				err = json.NewEncoder(w).Encode(rankMovement{
+					Kind:      kind,
+					ID:        id,
+					Positions: history.RankMovement(snapshots, id),
+				})
+				if err != nil {
+					log.Error("exporting rank movement failed", zap.Error(err))
+				}
+			})
+		})
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(adminMiddleware(adminToken))
+			r.Route("/watchlist", func(r chi.Router) {
+				r.Use(func(next http.Handler) http.Handler {
+					return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						if stats == nil {
+							w.WriteHeader(http.StatusNotFound)
+							w.Write([]byte("Racer history is not in use."))
+							return
+						}
+						next.ServeHTTP(w, r)
+					})
+				})
+				r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+					writeWatchlist(w, r, logger, stats)
+				})
+				r.Put("/{username}", func(w http.ResponseWriter, r *http.Request) {
+					log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+					err := stats.Watch(chi.URLParam(r, "username"))
+					if errors.Is(err, history.ErrInvalidUsername) {
+						w.WriteHeader(http.StatusBadRequest)
+						w.Write([]byte("Invalid watchlist request."))
+						return
+					}
+					if err != nil {
+						log.Error("adding racer to watchlist failed", zap.Error(err))
+						w.WriteHeader(http.StatusInternalServerError)
+						w.Write([]byte("Unable to update the watchlist."))
+						return
+					}
+					writeWatchlist(w, r, logger, stats)
+				})
+				r.Delete("/{username}", func(w http.ResponseWriter, r *http.Request) {
+					log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+					err := stats.Unwatch(chi.URLParam(r, "username"))
+					if errors.Is(err, history.ErrInvalidUsername) {
+						w.WriteHeader(http.StatusBadRequest)
+						w.Write([]byte("Invalid watchlist request."))
+						return
+					}
+					if err != nil {
+						log.Error("removing racer from watchlist failed", zap.Error(err))
+						w.WriteHeader(http.StatusInternalServerError)
+						w.Write([]byte("Unable to update the watchlist."))
+						return
+					}
+					writeWatchlist(w, r, logger, stats)
+				})
+			})
+		})
 	})
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Hi?"))
@@ -90,6 +919,28 @@ func NewAPIService(logger *zap.Logger, cacheManager *cache.Cache, corsOptions *c
 	return r
 }
 
+// adminMiddleware only lets through requests with the admin token as a bearer token.
+// The admin API is turned off when no token is configured.
+func adminMiddleware(token string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("Admin API is not enabled."))
+				return
+			}
+			given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte("Invalid admin token."))
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
 // loggerMiddleware is a middleware that logs the start and end of each request, along
 // with some useful data about what was requested, what the response status was,
 // and how long it took to return.
@@ -115,43 +966,123 @@ func loggerMiddleware(l *zap.Logger) func(next http.Handler) http.Handler {
 	}
 }
 
-// getPlayerData fetchces NT Bootstrap Data from the cache or the net
-func getPlayerData(ctx context.Context, logger *zap.Logger, cacheManager *cache.Cache, username string) (*nitrotype.NTPlayer, error) {
-	cacheName := "player_data_" + username
-	cacheSource, found := cacheManager.Get(cacheName)
-	if found {
-		source, ok := cacheSource.(nitrotype.NTPlayer)
-		if ok {
-			return &source, nil
-		}
-		logger.Warn("failed to read player data from cache")
+// proxyCarImage streams a car image from Nitro Type, for frontends that cannot load images from another origin.
+// client should go through the same proxies as the scrapes.
+func proxyCarImage(w http.ResponseWriter, r *http.Request, log *zap.Logger, client *http.Client, imageURL string) {
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, imageURL, nil)
+	if err != nil {
+		log.Error("creating car image request failed", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Unable to collect NT Car image. Please try again later."))
+		return
 	}
-	racer, err := nitrotype.GetPlayerData(ctx, username)
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get latest nitro type player data: %w", err)
+		log.Error("grabbing car image from nitro type failed", zap.Error(err))
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("Unable to collect NT Car image. Please try again later."))
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Warn("grabbing car image from nitro type failed", zap.String("url", imageURL), zap.Int("status", resp.StatusCode))
+		if resp.StatusCode == http.StatusNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("NT Car image was not found."))
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(fmt.Sprintf("Nitro Type responded with status %d. Please try again later.", resp.StatusCode)))
+		return
 	}
 
-	cacheManager.Set(cacheName, *racer, cache.DefaultExpiration)
-	return racer, nil
+	for _, header := range []string{"Content-Type", "Content-Length", "Cache-Control", "Last-Modified", "ETag"} {
+		if value := resp.Header.Get(header); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		log.Error("exporting car image failed", zap.Error(err))
+	}
+}
 
+// logGlobalsWarning logs NTGLOBALS sections the handler does not need that could not be decoded.
+func logGlobalsWarning(log *zap.Logger) func(err error) {
+	return func(err error) {
+		log.Warn("some NTGLOBALS sections could not be decoded", zap.Error(err))
+	}
 }
 
-// getBootstrapData fetchces NT Bootstrap Data from the cache or the net
-func getBootstrapData(logger *zap.Logger, cacheManager *cache.Cache) (*nitrotype.NTGlobalsLegacy, error) {
-	cacheSource, found := cacheManager.Get("bootstrap_data")
-	if found {
-		source, ok := cacheSource.(*nitrotype.NTGlobalsLegacy)
-		if ok {
-			return source, nil
+// previousSnapshot returns the snapshot taken before the one with the ID.
+func previousSnapshot(snapshots snapshot.Store, id string) (snapshot.Snapshot, []byte, error) {
+	list, err := snapshots.List()
+	if err != nil {
+		return snapshot.Snapshot{}, nil, err
+	}
+	for i := len(list) - 1; i > 0; i-- {
+		if list[i].ID == id {
+			return snapshots.Get(list[i-1].ID)
 		}
-		logger.Warn("failed to read bootstrap data from cache")
 	}
+	return snapshot.Snapshot{}, nil, snapshot.ErrNotFound
+}
+
+// writeWatchlist responds with the watched usernames.
+func writeWatchlist(w http.ResponseWriter, r *http.Request, logger *zap.Logger, stats history.Store) {
+	log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
+
+	usernames, err := stats.Watchlist()
+	if err != nil {
+		log.Error("reading watchlist failed", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Unable to read the watchlist."))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(usernames)
+	if err != nil {
+		log.Error("exporting watchlist failed", zap.Error(err))
+	}
+}
+
+// parseTimeParam reads a date, RFC 3339 time or unix time from a query parameter, returning fallback when it is empty.
+func parseTimeParam(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	if stamp, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(stamp, 0), nil
+	}
+	if output, err := time.Parse(time.RFC3339, value); err == nil {
+		return output, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// writeSnapshot responds with the snapshot read returns.
+func writeSnapshot(w http.ResponseWriter, r *http.Request, logger *zap.Logger, read func() (snapshot.Snapshot, []byte, error)) {
+	log := logger.With(zap.String("reqID", middleware.GetReqID(r.Context())))
 
-	source, err := nitrotype.GetBootstrapData(context.Background())
+	item, data, err := read()
+	if errors.Is(err, snapshot.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("NT Bootstrap snapshot was not found."))
+		return
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get latest nitro type bootstrap js: %w", err)
+		log.Error("reading bootstrap snapshot failed", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Unable to read NT Bootstrap snapshot."))
+		return
 	}
 
-	cacheManager.Set("bootstrap_data", source, cache.DefaultExpiration)
-	return source, nil
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(snapshotResult{Snapshot: item, Data: data})
+	if err != nil {
+		log.Error("exporting bootstrap snapshot failed", zap.Error(err))
+	}
 }
@@ -0,0 +1,121 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"nt-bootstrap-scraper/internal/app/serve/api"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"nt-bootstrap-scraper/pkg/nitrotype/nitrotypetest"
+	"testing"
+
+	"github.com/go-chi/cors"
+	"github.com/patrickmn/go-cache"
+	"go.uber.org/zap"
+)
+
+// newTestAPI serves the API in front of a fake Nitro Type site.
+func newTestAPI(t *testing.T) (*nitrotypetest.Server, http.Handler) {
+	t.Helper()
+	server := nitrotypetest.NewServer()
+	t.Cleanup(server.Close)
+
+	cacheManager := cache.New(cache.NoExpiration, cache.NoExpiration)
+	fetcher := server.Fetcher()
+	leaderboard := nitrotype.NewLeaderboardResolver(fetcher, cacheManager)
+	handler := api.NewAPIService(zap.NewNop(), fetcher, leaderboard, nil, nil, nil, nil, "", &cors.Options{})
+	return server, handler
+}
+
+func get(handler http.Handler, path string) (int, []byte) {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	body, _ := ioutil.ReadAll(w.Result().Body)
+	return w.Code, body
+}
+
+func TestBootstrap(t *testing.T) {
+	_, handler := newTestAPI(t)
+
+	status, body := get(handler, "/api/bootstrap")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", status, body)
+	}
+	var source nitrotype.NTGlobalsLegacy
+	if err := json.Unmarshal(body, &source); err != nil {
+		t.Fatal(err)
+	}
+	if source["CARS"] == nil || source["TOP_PLAYERS"] == nil {
+		t.Errorf("bootstrap is missing CARS or TOP_PLAYERS: %s", body)
+	}
+}
+
+func TestBootstrapCanceled(t *testing.T) {
+	_, handler := newTestAPI(t)
+
+	// A client that has gone away stops the scrape instead of it running to completion
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, path := range []string{"/api/bootstrap", "/api/bootstrap/schema"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx))
+		if w.Code == http.StatusOK {
+			t.Errorf("%s = %d, want the scrape to be canceled", path, w.Code)
+		}
+	}
+}
+
+func TestRacer(t *testing.T) {
+	_, handler := newTestAPI(t)
+
+	status, body := get(handler, "/api/racer/speedy")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", status, body)
+	}
+	var racer nitrotype.NTPlayer
+	if err := json.Unmarshal(body, &racer); err != nil {
+		t.Fatal(err)
+	}
+	if racer.UserID != 12 || racer.Username != "speedy" {
+		t.Errorf("racer = %+v", racer)
+	}
+
+	status, body = get(handler, "/api/racer/nobody")
+	if status != http.StatusNotFound || string(body) != "NT Player was not found." {
+		t.Errorf("missing racer = %d %q, want 404", status, body)
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	tests := []struct {
+		kind    nitrotype.PageKind
+		message string
+	}{
+		{kind: nitrotype.PageMaintenance, message: "Nitro Type is down for maintenance. Please try again later."},
+		{kind: nitrotype.PageChallenge, message: "Nitro Type is currently refusing our requests. Please try again later."},
+	}
+	for _, test := range tests {
+		t.Run(string(test.kind), func(t *testing.T) {
+			server, handler := newTestAPI(t)
+			server.SetUnavailable(test.kind)
+
+			for _, path := range []string{"/api/bootstrap", "/api/racer/speedy"} {
+				status, body := get(handler, path)
+				if status != http.StatusServiceUnavailable || string(body) != test.message {
+					t.Errorf("%s = %d %q, want 503 %q", path, status, body, test.message)
+				}
+			}
+		})
+	}
+}
+
+func TestLeaderboardNotReady(t *testing.T) {
+	_, handler := newTestAPI(t)
+
+	status, body := get(handler, "/api/leaderboard/players")
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("status = %d %q, want 503 before the first refresh", status, body)
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+)
+
+// scrapeErrorStatus picks the response status and message for a failed scrape.
+// A zero status is returned when the error is not one of the known scraper failures.
+func scrapeErrorStatus(err error) (int, string) {
+	var statusErr *nitrotype.HTTPStatusError
+	switch {
+	case errors.Is(err, nitrotype.ErrPlayerNotFound):
+		return http.StatusNotFound, "NT Player was not found."
+	case errors.Is(err, nitrotype.ErrTeamNotFound):
+		return http.StatusNotFound, "NT Team was not found."
+	case errors.Is(err, nitrotype.ErrBotChallenge):
+		return http.StatusServiceUnavailable, "Nitro Type is currently refusing our requests. Please try again later."
+	case errors.Is(err, nitrotype.ErrMaintenance):
+		return http.StatusServiceUnavailable, "Nitro Type is down for maintenance. Please try again later."
+	case errors.Is(err, nitrotype.ErrUpstreamError):
+		return http.StatusServiceUnavailable, "Nitro Type is currently unavailable. Please try again later."
+	case errors.Is(err, nitrotype.ErrTimeout):
+		return http.StatusGatewayTimeout, "Nitro Type took too long to respond. Please try again later."
+	case errors.Is(err, nitrotype.ErrNetwork):
+		return http.StatusBadGateway, "Unable to reach Nitro Type. Please try again later."
+	case errors.Is(err, nitrotype.ErrBrowserLaunch), errors.Is(err, nitrotype.ErrBrowserClosed):
+		return http.StatusServiceUnavailable, "Scraper is currently unavailable. Please try again later."
+	case errors.As(err, &statusErr):
+		if statusErr.StatusCode == http.StatusTooManyRequests {
+			return http.StatusServiceUnavailable, "Nitro Type is rate limiting our requests. Please try again later."
+		}
+		return http.StatusBadGateway, fmt.Sprintf("Nitro Type responded with status %d. Please try again later.", statusErr.StatusCode)
+	case errors.Is(err, nitrotype.ErrLeaderboardNotReady):
+		return http.StatusServiceUnavailable, "NT Leaderboard is still being resolved. Please try again later."
+	case errors.Is(err, nitrotype.ErrInvalidScoreboard):
+		return http.StatusBadRequest, "NT Scoreboard does not exist."
+	case errors.Is(err, nitrotype.ErrSeasonNotFound):
+		return http.StatusNotFound, "NT Season was not found."
+	case errors.Is(err, nitrotype.ErrCarNotFound):
+		return http.StatusNotFound, "NT Car was not found."
+	case errors.Is(err, nitrotype.ErrInvalidCarImage):
+		return http.StatusBadRequest, "NT Car image does not exist."
+	case errors.Is(err, nitrotype.ErrBootstrapScriptNotFound),
+		errors.Is(err, nitrotype.ErrNTGlobalsMissing),
+		errors.Is(err, nitrotype.ErrGlobalsDecode),
+		errors.Is(err, nitrotype.ErrTopPlayersParse),
+		errors.Is(err, nitrotype.ErrScoreboardParse):
+		return http.StatusBadGateway, "Nitro Type sent data we could not understand. Please try again later."
+	}
+	return 0, ""
+}
+
+// writeScrapeError responds to a failed scrape, fallback is the message for unclassified errors.
+func writeScrapeError(w http.ResponseWriter, err error, fallback string) {
+	status, message := scrapeErrorStatus(err)
+	if status == 0 {
+		status, message = http.StatusInternalServerError, fallback
+	}
+	w.WriteHeader(status)
+	w.Write([]byte(message))
+}
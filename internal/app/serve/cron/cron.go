@@ -2,40 +2,221 @@ package cron
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"nt-bootstrap-scraper/pkg/history"
 	"nt-bootstrap-scraper/pkg/nitrotype"
+	"nt-bootstrap-scraper/pkg/snapshot"
+	"time"
 
 	"github.com/go-logr/zapr"
 	"github.com/patrickmn/go-cache"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // NewCronService creates a new cron service ready to be activated
-func NewCronService(log *zap.Logger, cacheManager *cache.Cache) *cron.Cron {
+// Bootstrap scrapes are saved to snapshots, and watched racers and rank lists are recorded in stats, when they are not nil.
+func NewCronService(log *zap.Logger, cacheManager *cache.Cache, fetcher nitrotype.Fetcher, leaderboard *nitrotype.LeaderboardResolver, snapshots snapshot.Store, stats history.Store) *cron.Cron {
 	logger := zapr.NewLogger(log)
-	scrapeBootstrapFN := scrapeBootstrap(log, cacheManager)
+	scrapeBootstrapFN := scrapeBootstrap(log, cacheManager, fetcher, snapshots, stats)
 	c := cron.New(
 		cron.WithChain(cron.DelayIfStillRunning(logger)),
 	)
 	c.AddFunc("1,11,21,31,41,51 * * * *", scrapeBootstrapFN)
+	c.AddFunc("6,16,26,36,46,56 * * * *", scrapeScoreboards(log, cacheManager, fetcher))
+	resolveLeaderboardFN := resolveLeaderboard(log, leaderboard)
+	c.AddFunc("30 * * * *", resolveLeaderboardFN)
+	if stats != nil {
+		c.AddFunc("45 * * * *", recordRacerStats(log, fetcher, stats))
+	}
 
 	scrapeBootstrapFN()
+	// The leaderboard is unavailable until its first refresh, which takes too long to wait for here
+	go resolveLeaderboardFN()
 
 	return c
 }
 
 // scrapeBootstrap is the scheduled task function that collect Nitro Type Bootstrap file.
-func scrapeBootstrap(log *zap.Logger, cacheManager *cache.Cache) func() {
+func scrapeBootstrap(log *zap.Logger, cacheManager *cache.Cache, fetcher nitrotype.Fetcher, snapshots snapshot.Store, stats history.Store) func() {
 	log = log.With(
 		zap.String("job", "scrapeBootstrap"),
 	)
 
 	return func() {
-		source, err := nitrotype.GetBootstrapData(context.Background())
+		source, err := fetcher.GetBootstrapData(context.Background())
+		if err != nil {
+			log.Check(errorLevel(err), "failed to get latest bootstrap file").Write(zap.Error(err))
+			return
+		}
+		cacheManager.Set(nitrotype.BootstrapCacheKey, source, cache.DefaultExpiration)
+		log.Info("bootstrap file updated")
+
+		if snapshots != nil {
+			saveSnapshot(log, snapshots, source)
+		}
+		if stats != nil {
+			recordRanks(log, stats, source)
+		}
+
+		report, err := nitrotype.CheckSchema(*source)
+		if err != nil {
+			log.Warn("failed to check bootstrap schema", zap.Error(err))
+			return
+		}
+		if report.HasDrift() {
+			log.Warn("bootstrap schema drift detected",
+				zap.Strings("unknownKeys", report.UnknownKeys),
+				zap.Strings("missingKeys", report.MissingKeys),
+				zap.Any("mismatches", report.Mismatches),
+			)
+		}
+	}
+}
+
+// saveSnapshot records a scraped bootstrap file, a failure to save does not stop the rest of the scrape.
+func saveSnapshot(log *zap.Logger, snapshots snapshot.Store, source *nitrotype.NTGlobalsLegacy) {
+	data, err := json.Marshal(source)
+	if err != nil {
+		log.Error("failed to encode bootstrap snapshot", zap.Error(err))
+		return
+	}
+	saved, created, err := snapshots.Put(time.Now(), data)
+	if err != nil {
+		log.Error("failed to save bootstrap snapshot", zap.Error(err))
+		return
+	}
+	if !created {
+		log.Debug("bootstrap file unchanged since last snapshot", zap.String("snapshot", saved.ID))
+		return
+	}
+	log.Info("bootstrap snapshot saved", zap.String("snapshot", saved.ID), zap.String("hash", saved.Hash))
+}
+
+// recordRanks adds the TOP_PLAYERS and TOP_TEAMS lists of a scraped bootstrap file to the rank history.
+func recordRanks(log *zap.Logger, stats history.Store, source *nitrotype.NTGlobalsLegacy) {
+	// Other sections failing to decode do not matter here, only the rank lists
+	globals, err := source.Decode()
+	failed := map[string]error{}
+	var decodeErr *nitrotype.GlobalsDecodeError
+	if errors.As(err, &decodeErr) {
+		failed = decodeErr.Sections
+	}
+	now := time.Now()
+	for kind, section := range map[history.RankKind]string{
+		history.RankPlayers: "TOP_PLAYERS",
+		history.RankTeams:   "TOP_TEAMS",
+	} {
+		if err := failed[section]; err != nil {
+			log.Error("failed to decode bootstrap ranks", zap.String("kind", string(kind)), zap.Error(err))
+			continue
+		}
+		items := globals.TopPlayers
+		if kind == history.RankTeams {
+			items = globals.TopTeams
+		}
+		if err := stats.AddRanks(kind, history.RankSnapshot{Timestamp: now, Items: items}); err != nil {
+			log.Error("failed to record ranks", zap.String("kind", string(kind)), zap.Error(err))
+		}
+	}
+}
+
+// scrapeScoreboards is the scheduled task function that collects every Nitro Type scoreboard.
+func scrapeScoreboards(log *zap.Logger, cacheManager *cache.Cache, fetcher nitrotype.Fetcher) func() {
+	log = log.With(
+		zap.String("job", "scrapeScoreboards"),
+	)
+
+	return func() {
+		for _, kind := range nitrotype.ScoreboardKinds {
+			for _, timeframe := range nitrotype.ScoreboardTimeframes {
+				scoreboard, err := fetcher.GetScoreboard(context.Background(), kind, timeframe)
+				if err != nil {
+					log.Check(errorLevel(err), "failed to get latest scoreboard").Write(
+						zap.String("kind", string(kind)),
+						zap.String("timeframe", string(timeframe)),
+						zap.Error(err),
+					)
+					continue
+				}
+				cacheManager.Set(nitrotype.ScoreboardCacheKey(kind, timeframe), scoreboard, cache.DefaultExpiration)
+			}
+		}
+		log.Info("scoreboards updated")
+	}
+}
+
+// resolveLeaderboard is the scheduled task function that looks up the profiles of the top players and teams.
+func resolveLeaderboard(log *zap.Logger, leaderboard *nitrotype.LeaderboardResolver) func() {
+	log = log.With(
+		zap.String("job", "resolveLeaderboard"),
+	)
+
+	return func() {
+		profiles, err := leaderboard.Refresh(context.Background())
+		if err != nil {
+			log.Check(errorLevel(err), "failed to resolve leaderboard profiles").Write(zap.Error(err))
+			return
+		}
+		log.Info("leaderboard profiles updated",
+			zap.Int("players", len(profiles.Players)),
+			zap.Int("teams", len(profiles.Teams)),
+			zap.Int("failures", profiles.Failures),
+		)
+	}
+}
+
+// recordRacerStats is the scheduled task function that records the stats of every watched racer.
+func recordRacerStats(log *zap.Logger, fetcher nitrotype.Fetcher, stats history.Store) func() {
+	log = log.With(
+		zap.String("job", "recordRacerStats"),
+	)
+
+	return func() {
+		usernames, err := stats.Watchlist()
 		if err != nil {
-			log.Warn("failed to get latest bootstrap file", zap.Error(err))
+			log.Error("failed to read watchlist", zap.Error(err))
+			return
 		}
-		cacheManager.Set("bootstrap_data", source, cache.DefaultExpiration)
-		log.Info("bootstrap file updated", zap.Error(err))
+		if len(usernames) == 0 {
+			return
+		}
+
+		recorded := 0
+		results := nitrotype.GetPlayersData(context.Background(), usernames, nitrotype.PlayersOptions{Fetcher: fetcher})
+		for _, result := range results {
+			if result.Err != nil {
+				log.Check(errorLevel(result.Err), "failed to get watched racer").Write(
+					zap.String("username", result.Username),
+					zap.Error(result.Err),
+				)
+				continue
+			}
+			if err := stats.Add(result.Username, history.NewStatPoint(result.Player, time.Now())); err != nil {
+				log.Error("failed to record racer stats", zap.String("username", result.Username), zap.Error(err))
+				continue
+			}
+			recorded++
+		}
+		log.Info("racer stats recorded", zap.Int("watched", len(usernames)), zap.Int("recorded", recorded))
+	}
+}
+
+// errorLevel picks the log severity for a failed scrape.
+// Transient upstream problems are warnings, failures that mean the site changed or the scraper is broken are errors.
+func errorLevel(err error) zapcore.Level {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return zapcore.InfoLevel
+	case errors.Is(err, nitrotype.ErrBootstrapScriptNotFound),
+		errors.Is(err, nitrotype.ErrNTGlobalsMissing),
+		errors.Is(err, nitrotype.ErrTopPlayersParse),
+		errors.Is(err, nitrotype.ErrScoreboardParse),
+		errors.Is(err, nitrotype.ErrBrowserLaunch),
+		errors.Is(err, nitrotype.ErrBrowserClosed):
+		return zapcore.ErrorLevel
 	}
+	return zapcore.WarnLevel
 }
@@ -0,0 +1,88 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"nt-bootstrap-scraper/pkg/nitrotype/nitrotypetest"
+	"nt-bootstrap-scraper/pkg/snapshot"
+	"testing"
+
+	"github.com/patrickmn/go-cache"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestErrorLevel(t *testing.T) {
+	tests := []struct {
+		err  error
+		want zapcore.Level
+	}{
+		{err: context.Canceled, want: zapcore.InfoLevel},
+		{err: fmt.Errorf("scrape: %w", context.Canceled), want: zapcore.InfoLevel},
+		{err: nitrotype.ErrBootstrapScriptNotFound, want: zapcore.ErrorLevel},
+		{err: fmt.Errorf("decode: %w", nitrotype.ErrNTGlobalsMissing), want: zapcore.ErrorLevel},
+		{err: nitrotype.ErrBrowserClosed, want: zapcore.ErrorLevel},
+		{err: nitrotype.ErrTimeout, want: zapcore.WarnLevel},
+		{err: nitrotype.ErrMaintenance, want: zapcore.WarnLevel},
+	}
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			if got := errorLevel(test.err); got != test.want {
+				t.Errorf("errorLevel(%v) = %s, want %s", test.err, got, test.want)
+			}
+		})
+	}
+}
+
+func TestScrapeBootstrap(t *testing.T) {
+	server := nitrotypetest.NewServer()
+	defer server.Close()
+
+	snapshots, err := snapshot.OpenDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snapshots.Close()
+
+	cacheManager := cache.New(cache.NoExpiration, cache.NoExpiration)
+	scrape := scrapeBootstrap(zap.NewNop(), cacheManager, server.Fetcher(), snapshots, nil)
+	scrape()
+
+	cached, ok := cacheManager.Get(nitrotype.BootstrapCacheKey)
+	if !ok {
+		t.Fatal("bootstrap file was not cached")
+	}
+	if source := cached.(*nitrotype.NTGlobalsLegacy); (*source)["CARS"] == nil {
+		t.Errorf("cached bootstrap is missing CARS: %v", source)
+	}
+
+	// An unchanged bootstrap file is not saved twice
+	scrape()
+	saved, err := snapshots.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 {
+		t.Errorf("snapshots = %+v, want 1", saved)
+	}
+}
+
+func TestScrapeBootstrapUnavailable(t *testing.T) {
+	server := nitrotypetest.NewServer()
+	defer server.Close()
+	server.SetUnavailable(nitrotype.PageMaintenance)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	cacheManager := cache.New(cache.NoExpiration, cache.NoExpiration)
+	scrapeBootstrap(zap.New(core), cacheManager, server.Fetcher(), nil, nil)()
+
+	if _, ok := cacheManager.Get(nitrotype.BootstrapCacheKey); ok {
+		t.Error("bootstrap file was cached during maintenance")
+	}
+	failures := logs.FilterMessage("failed to get latest bootstrap file").All()
+	if len(failures) != 1 || failures[0].Level != zapcore.WarnLevel {
+		t.Errorf("logs = %+v, want one warning", logs.All())
+	}
+}
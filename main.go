@@ -6,13 +6,18 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"nt-bootstrap-scraper/internal/app/serve/api"
 	"nt-bootstrap-scraper/internal/app/serve/cron"
+	"nt-bootstrap-scraper/pkg/history"
 	"nt-bootstrap-scraper/pkg/nitrotype"
+	"nt-bootstrap-scraper/pkg/snapshot"
 	"os"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/go-chi/cors"
@@ -22,6 +27,69 @@ import (
 	"go.uber.org/zap"
 )
 
+var (
+	fetcherFlag = &cli.StringFlag{
+		Name:    "fetcher",
+		Value:   "chrome",
+		Usage:   "how to collect data from nitro type (http, chrome or fixture)",
+		EnvVars: []string{"FETCHER"},
+	}
+	baseURLFlag = &cli.StringFlag{
+		Name:    "base_url",
+		Value:   nitrotype.DefaultBaseURL,
+		Usage:   "origin of the nitro type site to collect data from",
+		EnvVars: []string{"BASE_URL"},
+	}
+	fixtureDirFlag = &cli.StringFlag{
+		Name:    "fixture_dir",
+		Usage:   "directory of saved json output to replay when using the fixture fetcher",
+		EnvVars: []string{"FIXTURE_DIR"},
+	}
+	retryAttemptsFlag = &cli.IntFlag{
+		Name:    "retry_attempts",
+		Value:   nitrotype.DefaultRetryPolicy.MaxAttempts,
+		Usage:   "attempts made at each scrape before giving up on transient failures",
+		EnvVars: []string{"RETRY_ATTEMPTS"},
+	}
+	retryBaseDelayFlag = &cli.DurationFlag{
+		Name:    "retry_base_delay",
+		Value:   nitrotype.DefaultRetryPolicy.BaseDelay,
+		Usage:   "wait after the first failed attempt, doubled after each following failure",
+		EnvVars: []string{"RETRY_BASE_DELAY"},
+	}
+	retryMaxDelayFlag = &cli.DurationFlag{
+		Name:    "retry_max_delay",
+		Value:   nitrotype.DefaultRetryPolicy.MaxDelay,
+		Usage:   "longest wait between attempts",
+		EnvVars: []string{"RETRY_MAX_DELAY"},
+	}
+	retryJitterFlag = &cli.Float64Flag{
+		Name:    "retry_jitter",
+		Value:   nitrotype.DefaultRetryPolicy.Jitter,
+		Usage:   "fraction of each wait to randomise (0 to 1)",
+		EnvVars: []string{"RETRY_JITTER"},
+	}
+	retryFlags    = []cli.Flag{retryAttemptsFlag, retryBaseDelayFlag, retryMaxDelayFlag, retryJitterFlag}
+	proxyURLsFlag = &cli.StringSliceFlag{
+		Name:    "proxy",
+		Usage:   "outbound proxy url (http, https or socks5, optionally with user:pass@, which chrome only supports for http proxies), repeat to rotate between several",
+		EnvVars: []string{"PROXIES"},
+	}
+	proxyFailuresFlag = &cli.IntFlag{
+		Name:    "proxy_failures",
+		Value:   nitrotype.DefaultProxyFailureThreshold,
+		Usage:   "failures in a row before a proxy is taken out of rotation",
+		EnvVars: []string{"PROXY_FAILURES"},
+	}
+	proxyCooldownFlag = &cli.DurationFlag{
+		Name:    "proxy_cooldown",
+		Value:   nitrotype.DefaultProxyCooldown,
+		Usage:   "how long a failing proxy is left out of rotation",
+		EnvVars: []string{"PROXY_COOLDOWN"},
+	}
+	proxyFlags = []cli.Flag{proxyURLsFlag, proxyFailuresFlag, proxyCooldownFlag}
+)
+
 func main() {
 	app := &cli.App{
 		Usage: "runs an api server containing nitro type booststrap data.",
@@ -75,9 +143,91 @@ func main() {
 						Usage:   "TTL to cache CORS",
 						EnvVars: []string{"CORS_ALLOW_CREDENTIALS"},
 					},
+					fetcherFlag,
+					baseURLFlag,
+					fixtureDirFlag,
+					retryAttemptsFlag,
+					retryBaseDelayFlag,
+					retryMaxDelayFlag,
+					retryJitterFlag,
+					proxyURLsFlag,
+					proxyFailuresFlag,
+					proxyCooldownFlag,
+					&cli.StringFlag{
+						Name:    "snapshot_store",
+						Usage:   "where to keep every bootstrap file scraped, bolt:{file} or dir:{directory}",
+						EnvVars: []string{"SNAPSHOT_STORE"},
+					},
+					&cli.StringFlag{
+						Name:    "history_store",
+						Usage:   "where to keep the stats of watched racers and the rank lists of each scrape, bolt:{file} or dir:{directory}",
+						EnvVars: []string{"HISTORY_STORE"},
+					},
+					&cli.StringSliceFlag{
+						Name:    "watch",
+						Usage:   "usernames to add to the watchlist of racers whose stats are recorded",
+						EnvVars: []string{"WATCHLIST"},
+					},
+					&cli.StringFlag{
+						Name:    "admin_token",
+						Usage:   "bearer token for the admin API, which is turned off when empty",
+						EnvVars: []string{"ADMIN_TOKEN"},
+					},
+					&cli.IntFlag{
+						Name:    "chrome_tabs",
+						Value:   nitrotype.DefaultMaxTabs,
+						Usage:   "maximum number of chrome tabs open at once",
+						EnvVars: []string{"CHROME_TABS"},
+					},
 				},
 				Usage: "runs a mini api server to serve nitro type boostrap file data.",
 				Action: func(c *cli.Context) error {
+					var browser *nitrotype.Browser
+					if c.String("fetcher") == "chrome" {
+						browser = nitrotype.NewBrowser(c.Int("chrome_tabs"))
+						defer browser.Close()
+					}
+
+					proxies, err := newProxyPool(c)
+					if err != nil {
+						return err
+					}
+					fetcher, err := newFetcher(c, browser, proxies)
+					if err != nil {
+						return err
+					}
+
+					var snapshots snapshot.Store
+					if spec := c.String("snapshot_store"); spec != "" {
+						snapshots, err = snapshot.Open(spec)
+						if err != nil {
+							return err
+						}
+						defer snapshots.Close()
+					}
+
+					var stats history.Store
+					if spec := c.String("history_store"); spec != "" {
+						stats, err = history.Open(spec)
+						if err != nil {
+							return err
+						}
+						defer stats.Close()
+					}
+					for _, value := range c.StringSlice("watch") {
+						for _, username := range strings.Split(value, ",") {
+							if username = strings.TrimSpace(username); username == "" {
+								continue
+							}
+							if stats == nil {
+								return fmt.Errorf("history_store required to watch racers")
+							}
+							if err := stats.Watch(username); err != nil {
+								return err
+							}
+						}
+					}
+
 					ctx, cancel := context.WithCancel(c.Context)
 					cacheManager := cache.New(10*time.Minute, 15*time.Minute)
 
@@ -100,8 +250,13 @@ func main() {
 					}
 					defer logger.Sync()
 
-					apiService := api.NewAPIService(logger, cacheManager, corsOptions)
-					cronService := cron.NewCronService(logger, cacheManager)
+					fetcher = nitrotype.NewRetryFetcher(fetcher, retryPolicy(c, logRetryAttempt(logger)))
+
+					cachingFetcher := nitrotype.NewCachingFetcher(fetcher, cacheManager)
+					leaderboard := nitrotype.NewLeaderboardResolver(cachingFetcher, cacheManager)
+
+					apiService := api.NewAPIService(logger, cachingFetcher, leaderboard, browser, proxies, snapshots, stats, c.String("admin_token"), corsOptions)
+					cronService := cron.NewCronService(logger, cacheManager, fetcher, leaderboard, snapshots, stats)
 
 					server := &http.Server{
 						Addr:    apiAddr,
@@ -135,7 +290,7 @@ func main() {
 						}
 						cancel()
 					})
-					err := g.Run()
+					err = g.Run()
 					if errors.Is(err, run.SignalError{Signal: os.Interrupt}) {
 						logger.Fatal("service interrupted")
 					}
@@ -147,8 +302,31 @@ func main() {
 				Name:    "bootstrap",
 				Aliases: []string{"b"},
 				Usage:   "grabs the latest nitro type bootstrap file data.",
+				Flags: append([]cli.Flag{
+					fetcherFlag,
+					baseURLFlag,
+					fixtureDirFlag,
+					&cli.BoolFlag{
+						Name:  "typed",
+						Usage: "output only the fields known to the typed NTGlobals model",
+					},
+				}, append(retryFlags, proxyFlags...)...),
 				Action: func(c *cli.Context) error {
-					source, err := nitrotype.GetBootstrapData(context.Background())
+					proxies, err := newProxyPool(c)
+					if err != nil {
+						return err
+					}
+					fetcher, err := newFetcher(c, nil, proxies)
+					if err != nil {
+						return err
+					}
+					fetcher = nitrotype.NewRetryFetcher(fetcher, retryPolicy(c, printRetryAttempt))
+					var source interface{}
+					if c.Bool("typed") {
+						source, err = nitrotype.GetRequiredGlobals(context.Background(), fetcher, printGlobalsWarning)
+					} else {
+						source, err = fetcher.GetBootstrapData(context.Background())
+					}
 					if err != nil {
 						return fmt.Errorf("unable to download bootstrap.js: %w", err)
 					}
@@ -161,17 +339,137 @@ func main() {
 				},
 			},
 			{
-				Name:    "player",
-				Aliases: []string{"p"},
-				Usage:   "grabs the latest nitro type player data.",
+				Name:  "schema-check",
+				Usage: "compares the latest nitro type bootstrap file data against the typed model.",
+				Flags: append([]cli.Flag{
+					fetcherFlag,
+					baseURLFlag,
+					fixtureDirFlag,
+				}, append(retryFlags, proxyFlags...)...),
 				Action: func(c *cli.Context) error {
-					racer := c.Args().Get(0)
-					if racer == "" {
+					proxies, err := newProxyPool(c)
+					if err != nil {
+						return err
+					}
+					fetcher, err := newFetcher(c, nil, proxies)
+					if err != nil {
+						return err
+					}
+					fetcher = nitrotype.NewRetryFetcher(fetcher, retryPolicy(c, printRetryAttempt))
+					source, err := fetcher.GetBootstrapData(context.Background())
+					if err != nil {
+						return fmt.Errorf("unable to download bootstrap.js: %w", err)
+					}
+					report, err := nitrotype.CheckSchema(*source)
+					if err != nil {
+						return fmt.Errorf("unable to check schema: %w", err)
+					}
+					output, err := json.MarshalIndent(report, "", "  ")
+					if err != nil {
+						return fmt.Errorf("unable to marshal to json: %w", err)
+					}
+					fmt.Println(string(output))
+					if report.HasDrift() {
+						return fmt.Errorf("schema drift detected")
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "player",
+				Aliases:   []string{"p"},
+				Usage:     "grabs the latest nitro type player data.",
+				ArgsUsage: "username [username...]",
+				Flags: append([]cli.Flag{
+					fetcherFlag,
+					baseURLFlag,
+					fixtureDirFlag,
+					&cli.IntFlag{
+						Name:  "concurrency",
+						Value: nitrotype.DefaultMaxTabs,
+						Usage: "number of players to look up at once when given several usernames",
+					},
+				}, append(retryFlags, proxyFlags...)...),
+				Action: func(c *cli.Context) error {
+					racers := c.Args().Slice()
+					if len(racers) == 0 {
 						return fmt.Errorf("username required")
 					}
-					source, err := nitrotype.GetPlayerData(context.Background(), racer)
+					proxies, err := newProxyPool(c)
+					if err != nil {
+						return err
+					}
+
+					// Share one Chrome between the lookups of a batch
+					var browser *nitrotype.Browser
+					if len(racers) > 1 && c.String("fetcher") == "chrome" {
+						browser = nitrotype.NewBrowser(c.Int("concurrency"))
+						defer browser.Close()
+					}
+
+					fetcher, err := newFetcher(c, browser, proxies)
+					if err != nil {
+						return err
+					}
+					fetcher = nitrotype.NewRetryFetcher(fetcher, retryPolicy(c, printRetryAttempt))
+
+					var source interface{}
+					if len(racers) == 1 {
+						source, err = fetcher.GetPlayerData(context.Background(), racers[0])
+						if err != nil {
+							return fmt.Errorf("unable to download player data: %w", err)
+						}
+					} else {
+						results := nitrotype.GetPlayersData(context.Background(), racers, nitrotype.PlayersOptions{
+							Fetcher:     fetcher,
+							Concurrency: c.Int("concurrency"),
+						})
+						failed := 0
+						for _, result := range results {
+							if result.Err != nil {
+								log.Printf("unable to download player data for %s: %s", result.Username, result.Err)
+								failed++
+							}
+						}
+						if failed == len(results) {
+							return fmt.Errorf("unable to download player data for any of the %d players", failed)
+						}
+						source = results
+					}
+					output, err := json.Marshal(&source)
+					if err != nil {
+						return fmt.Errorf("unable to marshal to json: %w", err)
+					}
+					fmt.Println(string(output))
+					return nil
+				},
+			},
+			{
+				Name:    "team",
+				Aliases: []string{"t"},
+				Usage:   "grabs the latest nitro type team data.",
+				Flags: append([]cli.Flag{
+					fetcherFlag,
+					baseURLFlag,
+					fixtureDirFlag,
+				}, append(retryFlags, proxyFlags...)...),
+				Action: func(c *cli.Context) error {
+					tag := c.Args().Get(0)
+					if tag == "" {
+						return fmt.Errorf("team tag required")
+					}
+					proxies, err := newProxyPool(c)
+					if err != nil {
+						return err
+					}
+					fetcher, err := newFetcher(c, nil, proxies)
+					if err != nil {
+						return err
+					}
+					fetcher = nitrotype.NewRetryFetcher(fetcher, retryPolicy(c, printRetryAttempt))
+					source, err := fetcher.GetTeamData(context.Background(), tag)
 					if err != nil {
-						return fmt.Errorf("unable to download player data: %w", err)
+						return fmt.Errorf("unable to download team data: %w", err)
 					}
 					output, err := json.Marshal(&source)
 					if err != nil {
@@ -181,6 +479,155 @@ func main() {
 					return nil
 				},
 			},
+			{
+				Name:      "diff",
+				Usage:     "compares the cars, loot, shop and other entities of two saved bootstrap files.",
+				ArgsUsage: "from.json to.json",
+				Action: func(c *cli.Context) error {
+					if c.Args().Len() != 2 {
+						return fmt.Errorf("two bootstrap files required")
+					}
+					var sources [2]nitrotype.NTGlobalsLegacy
+					for i := range sources {
+						data, err := ioutil.ReadFile(c.Args().Get(i))
+						if err != nil {
+							return fmt.Errorf("unable to read bootstrap file: %w", err)
+						}
+						if err := json.Unmarshal(data, &sources[i]); err != nil {
+							return fmt.Errorf("unable to parse bootstrap file %s: %w", c.Args().Get(i), err)
+						}
+					}
+					diff, err := nitrotype.DiffBootstrap(sources[0], sources[1])
+					if err != nil {
+						return fmt.Errorf("unable to compare bootstrap files: %w", err)
+					}
+					output, err := json.MarshalIndent(diff, "", "  ")
+					if err != nil {
+						return fmt.Errorf("unable to marshal to json: %w", err)
+					}
+					fmt.Println(string(output))
+					return nil
+				},
+			},
+			{
+				Name:  "cash",
+				Usage: "works out the fee and weekly allowance left when sending cash.",
+				Flags: append([]cli.Flag{
+					fetcherFlag,
+					baseURLFlag,
+					fixtureDirFlag,
+					&cli.Int64Flag{
+						Name:     "amount",
+						Usage:    "amount of cash to send",
+						Required: true,
+					},
+					&cli.Int64Flag{
+						Name:  "sent",
+						Usage: "amount of cash already sent this week",
+					},
+					&cli.IntFlag{
+						Name:  "level",
+						Usage: "level of the sender, taken from the sender's profile when --username is given",
+					},
+					&cli.Int64Flag{
+						Name:  "created",
+						Usage: "unix time the sender's account was created, taken from the sender's profile when --username is given",
+					},
+					&cli.StringFlag{
+						Name:  "username",
+						Usage: "sender to look up the level and account age of",
+					},
+					&cli.BoolFlag{
+						Name:  "team",
+						Usage: "use the weekly limit for sending to team members",
+					},
+				}, append(retryFlags, proxyFlags...)...),
+				Action: func(c *cli.Context) error {
+					proxies, err := newProxyPool(c)
+					if err != nil {
+						return err
+					}
+					fetcher, err := newFetcher(c, nil, proxies)
+					if err != nil {
+						return err
+					}
+					fetcher = nitrotype.NewRetryFetcher(fetcher, retryPolicy(c, printRetryAttempt))
+
+					transfer := nitrotype.CashTransfer{
+						SenderLevel:  c.Int("level"),
+						CreatedStamp: c.Int64("created"),
+						Amount:       c.Int64("amount"),
+						SentThisWeek: c.Int64("sent"),
+						Team:         c.Bool("team"),
+					}
+					if username := c.String("username"); username != "" {
+						racer, err := fetcher.GetPlayerData(context.Background(), username)
+						if err != nil {
+							return fmt.Errorf("unable to download player data: %w", err)
+						}
+						if !c.IsSet("level") {
+							transfer.SenderLevel = racer.Level
+						}
+						if !c.IsSet("created") {
+							transfer.CreatedStamp = int64(racer.CreatedStamp)
+						}
+					}
+
+					globals, err := nitrotype.GetRequiredGlobals(context.Background(), fetcher, printGlobalsWarning, "CASH_SENDING")
+					if err != nil {
+						return fmt.Errorf("unable to download bootstrap.js: %w", err)
+					}
+					quote := globals.CashSending.Quote(transfer, time.Now())
+					output, err := json.MarshalIndent(quote, "", "  ")
+					if err != nil {
+						return fmt.Errorf("unable to marshal to json: %w", err)
+					}
+					fmt.Println(string(output))
+					if !quote.Allowed {
+						return fmt.Errorf("transfer not allowed: %s", strings.Join(quote.Reasons, ", "))
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "shop",
+				Usage: "prints how long is left on the shop and dealerships.",
+				Flags: append([]cli.Flag{
+					fetcherFlag,
+					baseURLFlag,
+					fixtureDirFlag,
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "include shop sections and dealerships that are not open right now",
+					},
+				}, append(retryFlags, proxyFlags...)...),
+				Action: func(c *cli.Context) error {
+					proxies, err := newProxyPool(c)
+					if err != nil {
+						return err
+					}
+					fetcher, err := newFetcher(c, nil, proxies)
+					if err != nil {
+						return err
+					}
+					fetcher = nitrotype.NewRetryFetcher(fetcher, retryPolicy(c, printRetryAttempt))
+					globals, err := nitrotype.GetRequiredGlobals(context.Background(), fetcher, printGlobalsWarning, "SHOP", "DEALERSHIP", "CARS", "LOOT")
+					if err != nil {
+						return fmt.Errorf("unable to download bootstrap.js: %w", err)
+					}
+
+					timeline := nitrotype.NewShopTimeline(globals, time.Now())
+					next := timeline.NextRotation()
+					if !c.Bool("all") {
+						timeline = timeline.Current()
+					}
+					printShopTimeline(os.Stdout, timeline)
+					if next != nil {
+						fmt.Printf("\nnext rotation in %s (%s)\n", countdown(next.InSeconds), next.At.Local().Format(time.RFC1123))
+					}
+					return nil
+				},
+			},
 		},
 	}
 
@@ -189,3 +636,143 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// newFetcher returns the data source selected by the fetcher flags.
+// Chrome scrapes use tabs from browser when given, otherwise a new Chrome is started each time.
+// Requests are spread across proxies when given.
+func newFetcher(c *cli.Context, browser *nitrotype.Browser, proxies *nitrotype.ProxyPool) (nitrotype.Fetcher, error) {
+	var fetcher nitrotype.Fetcher
+	switch c.String("fetcher") {
+	case "chrome":
+		if proxies != nil {
+			if err := proxies.CheckChrome(); err != nil {
+				return nil, err
+			}
+		}
+		fetcher = nitrotype.ChromeFetcher{BaseURL: c.String("base_url"), Browser: browser}
+	case "http":
+		fetcher = nitrotype.HTTPFetcher{BaseURL: c.String("base_url")}
+	case "fixture":
+		dir := c.String("fixture_dir")
+		if dir == "" {
+			return nil, fmt.Errorf("fixture_dir required for the fixture fetcher")
+		}
+		return nitrotype.FixtureFetcher{Dir: dir}, nil
+	default:
+		return nil, fmt.Errorf("unknown fetcher %q (expected http, chrome or fixture)", c.String("fetcher"))
+	}
+	if proxies != nil {
+		fetcher = nitrotype.NewProxyFetcher(fetcher, proxies)
+	}
+	return fetcher, nil
+}
+
+// newProxyPool returns the proxies selected by the proxy flags, or nil to connect directly.
+func newProxyPool(c *cli.Context) (*nitrotype.ProxyPool, error) {
+	var proxyURLs []string
+	for _, value := range c.StringSlice("proxy") {
+		for _, proxyURL := range strings.Split(value, ",") {
+			if proxyURL = strings.TrimSpace(proxyURL); proxyURL != "" {
+				proxyURLs = append(proxyURLs, proxyURL)
+			}
+		}
+	}
+	if len(proxyURLs) == 0 {
+		return nil, nil
+	}
+	return nitrotype.NewProxyPool(proxyURLs, c.Int("proxy_failures"), c.Duration("proxy_cooldown"))
+}
+
+// retryPolicy returns the retry policy selected by the retry flags.
+func retryPolicy(c *cli.Context, onAttempt func(nitrotype.RetryAttempt)) nitrotype.RetryPolicy {
+	return nitrotype.RetryPolicy{
+		MaxAttempts: c.Int("retry_attempts"),
+		BaseDelay:   c.Duration("retry_base_delay"),
+		MaxDelay:    c.Duration("retry_max_delay"),
+		Jitter:      c.Float64("retry_jitter"),
+		OnAttempt:   onAttempt,
+	}
+}
+
+// logRetryAttempt logs each scrape attempt, failures are only warnings while another attempt is due.
+func logRetryAttempt(logger *zap.Logger) func(nitrotype.RetryAttempt) {
+	logger = logger.Named("retry")
+	return func(attempt nitrotype.RetryAttempt) {
+		fields := []zap.Field{
+			zap.String("op", attempt.Op),
+			zap.Int("attempt", attempt.Attempt),
+		}
+		switch {
+		case attempt.Err == nil:
+			logger.Debug("scrape attempt succeeded", fields...)
+		case attempt.Retrying:
+			logger.Warn("scrape attempt failed, retrying", append(fields, zap.Duration("delay", attempt.Delay), zap.Error(attempt.Err))...)
+		default:
+			logger.Info("scrape attempt failed, not retrying", append(fields, zap.Error(attempt.Err))...)
+		}
+	}
+}
+
+// printRetryAttempt reports failed attempts on stderr so command output stays valid JSON.
+func printRetryAttempt(attempt nitrotype.RetryAttempt) {
+	if attempt.Retrying {
+		log.Printf("%s attempt %d failed, retrying in %s: %s", attempt.Op, attempt.Attempt, attempt.Delay.Round(time.Millisecond), attempt.Err)
+	}
+}
+
+// printGlobalsWarning reports NTGLOBALS sections the command does not need that could not be decoded.
+func printGlobalsWarning(err error) {
+	log.Printf("warning: %s", err)
+}
+
+// printShopTimeline writes a table of shop sections and dealerships with the time left on each.
+func printShopTimeline(w io.Writer, timeline *nitrotype.ShopTimeline) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "SECTION\tSTATUS\tENDS IN\tITEMS")
+	for _, rotation := range timeline.Shop {
+		status, left := "open", countdown(rotation.RemainingSeconds)
+		switch {
+		case rotation.StartsInSeconds > 0:
+			status = "opens in " + countdown(rotation.StartsInSeconds)
+		case !rotation.Active:
+			status, left = "closed", "-"
+		}
+		fmt.Fprintf(table, "shop/%s\t%s\t%s\t%s\n", rotation.Category, status, left, shopItemNames(rotation.Items))
+	}
+	for _, rotation := range timeline.Dealerships {
+		status, left := "open", "never"
+		if rotation.ExpiresAt != nil {
+			left = countdown(rotation.RemainingSeconds)
+		}
+		if !rotation.Active {
+			status, left = "closed", "-"
+		}
+		fmt.Fprintf(table, "dealership/%s\t%s\t%s\t%s\n", rotation.AssetKey, status, left, shopItemNames(rotation.Items))
+	}
+	table.Flush()
+}
+
+// shopItemNames lists items by name, falling back to the type and ID for items missing from the catalogue.
+func shopItemNames(items []nitrotype.ShopTimeItem) string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Name != "" {
+			names = append(names, item.Name)
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s #%d", item.Type, item.ID))
+	}
+	return strings.Join(names, ", ")
+}
+
+// countdown formats seconds like 2d03h04m05s.
+func countdown(seconds int64) string {
+	left := time.Duration(seconds) * time.Second
+	days := left / (24 * time.Hour)
+	left -= days * 24 * time.Hour
+	output := fmt.Sprintf("%02dh%02dm%02ds", int(left.Hours()), int(left.Minutes())%60, int(left.Seconds())%60)
+	if days > 0 {
+		output = fmt.Sprintf("%dd%s", days, output)
+	}
+	return output
+}
@@ -0,0 +1,218 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	statsBucket     = []byte("stats")
+	watchlistBucket = []byte("watchlist")
+	ranksBucket     = []byte("ranks")
+)
+
+// BoltStore keeps history in a single BoltDB file.
+// Each racer has a bucket inside stats, and each rank list a bucket inside ranks, keyed by timestamp
+// so a cursor can seek to the start of a range.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens or creates a BoltDB history store at path.
+func OpenBolt(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open history database: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{statsBucket, watchlistBucket, ranksBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to create history buckets: %w", err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Add(username string, point StatPoint) error {
+	username, err := NormalizeUsername(username)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(point)
+	if err != nil {
+		return fmt.Errorf("unable to encode stats: %w", err)
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		racer, err := tx.Bucket(statsBucket).CreateBucketIfNotExists([]byte(username))
+		if err != nil {
+			return err
+		}
+		return racer.Put([]byte(point.Timestamp.UTC().Format(keyLayout)), value)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to save stats: %w", err)
+	}
+	return nil
+}
+
+func (s *BoltStore) Range(username string, since time.Time, until time.Time) ([]StatPoint, error) {
+	username, err := NormalizeUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	output := []StatPoint{}
+	end := until.UTC().Format(keyLayout)
+	err = s.db.View(func(tx *bolt.Tx) error {
+		racer := tx.Bucket(statsBucket).Bucket([]byte(username))
+		if racer == nil {
+			return nil
+		}
+		c := racer.Cursor()
+		for key, value := c.Seek([]byte(since.UTC().Format(keyLayout))); key != nil && string(key) < end; key, value = c.Next() {
+			var point StatPoint
+			if err := json.Unmarshal(value, &point); err != nil {
+				return err
+			}
+			output = append(output, point)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read stats: %w", err)
+	}
+	return output, nil
+}
+
+func (s *BoltStore) Watchlist() ([]string, error) {
+	output := []string{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(watchlistBucket).ForEach(func(key, _ []byte) error {
+			output = append(output, string(key))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read watchlist: %w", err)
+	}
+	return output, nil
+}
+
+func (s *BoltStore) Watch(username string) error {
+	username, err := NormalizeUsername(username)
+	if err != nil {
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(watchlistBucket).Put([]byte(username), []byte{})
+	})
+	if err != nil {
+		return fmt.Errorf("unable to save watchlist: %w", err)
+	}
+	return nil
+}
+
+func (s *BoltStore) Unwatch(username string) error {
+	username, err := NormalizeUsername(username)
+	if err != nil {
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(watchlistBucket).Delete([]byte(username))
+	})
+	if err != nil {
+		return fmt.Errorf("unable to save watchlist: %w", err)
+	}
+	return nil
+}
+
+func (s *BoltStore) AddRanks(kind RankKind, snapshot RankSnapshot) error {
+	if err := ValidateRankKind(kind); err != nil {
+		return err
+	}
+	snapshot.Timestamp = snapshot.Timestamp.UTC()
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("unable to encode ranks: %w", err)
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		ranks, err := tx.Bucket(ranksBucket).CreateBucketIfNotExists([]byte(kind))
+		if err != nil {
+			return err
+		}
+		return ranks.Put([]byte(snapshot.Timestamp.Format(keyLayout)), value)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to save ranks: %w", err)
+	}
+	return nil
+}
+
+func (s *BoltStore) Ranks(kind RankKind, since time.Time, until time.Time) ([]RankSnapshot, error) {
+	if err := ValidateRankKind(kind); err != nil {
+		return nil, err
+	}
+	output := []RankSnapshot{}
+	end := until.UTC().Format(keyLayout)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		ranks := tx.Bucket(ranksBucket).Bucket([]byte(kind))
+		if ranks == nil {
+			return nil
+		}
+		c := ranks.Cursor()
+		for key, value := c.Seek([]byte(since.UTC().Format(keyLayout))); key != nil && string(key) < end; key, value = c.Next() {
+			var snapshot RankSnapshot
+			if err := json.Unmarshal(value, &snapshot); err != nil {
+				return err
+			}
+			output = append(output, snapshot)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read ranks: %w", err)
+	}
+	return output, nil
+}
+
+func (s *BoltStore) LastRanks(kind RankKind, n int) ([]RankSnapshot, error) {
+	if err := ValidateRankKind(kind); err != nil {
+		return nil, err
+	}
+	output := []RankSnapshot{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		ranks := tx.Bucket(ranksBucket).Bucket([]byte(kind))
+		if ranks == nil {
+			return nil
+		}
+		c := ranks.Cursor()
+		for key, value := c.Last(); key != nil && len(output) < n; key, value = c.Prev() {
+			var snapshot RankSnapshot
+			if err := json.Unmarshal(value, &snapshot); err != nil {
+				return err
+			}
+			output = append(output, snapshot)
+		}
+		// The cursor walked back from the newest
+		for i, j := 0, len(output)-1; i < j; i, j = i+1, j-1 {
+			output[i], output[j] = output[j], output[i]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read ranks: %w", err)
+	}
+	return output, nil
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
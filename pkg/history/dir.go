@@ -0,0 +1,261 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DirStore keeps history as plain files, stats/{username}.jsonl has one line per recorded point,
+// ranks/{kind}.jsonl one line per scraped rank list and watchlist.json lists the watched usernames.
+type DirStore struct {
+	dir string
+
+	// mu serialises writes to the stats, ranks and watchlist files
+	mu sync.Mutex
+}
+
+// OpenDir opens or creates a directory history store.
+func OpenDir(dir string) (*DirStore, error) {
+	for _, name := range []string{"stats", "ranks"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			return nil, fmt.Errorf("unable to create history directory: %w", err)
+		}
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+func (s *DirStore) Add(username string, point StatPoint) error {
+	username, err := NormalizeUsername(username)
+	if err != nil {
+		return err
+	}
+	point.Timestamp = point.Timestamp.UTC()
+	if err := s.appendLine(s.statsPath(username), point); err != nil {
+		return fmt.Errorf("unable to save stats: %w", err)
+	}
+	return nil
+}
+
+func (s *DirStore) Range(username string, since time.Time, until time.Time) ([]StatPoint, error) {
+	username, err := NormalizeUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	output := []StatPoint{}
+	err = s.readLines(s.statsPath(username), func(line []byte) error {
+		var point StatPoint
+		if err := json.Unmarshal(line, &point); err != nil {
+			return err
+		}
+		if !point.Timestamp.Before(since) && point.Timestamp.Before(until) {
+			output = append(output, point)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read stats: %w", err)
+	}
+	// Points are appended as they are taken, but clock changes could still put them out of order
+	sort.SliceStable(output, func(i, j int) bool {
+		return output[i].Timestamp.Before(output[j].Timestamp)
+	})
+	return output, nil
+}
+
+func (s *DirStore) Watchlist() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.watchlist()
+}
+
+func (s *DirStore) Watch(username string) error {
+	username, err := NormalizeUsername(username)
+	if err != nil {
+		return err
+	}
+	return s.updateWatchlist(func(usernames map[string]bool) {
+		usernames[username] = true
+	})
+}
+
+func (s *DirStore) Unwatch(username string) error {
+	username, err := NormalizeUsername(username)
+	if err != nil {
+		return err
+	}
+	return s.updateWatchlist(func(usernames map[string]bool) {
+		delete(usernames, username)
+	})
+}
+
+func (s *DirStore) AddRanks(kind RankKind, snapshot RankSnapshot) error {
+	if err := ValidateRankKind(kind); err != nil {
+		return err
+	}
+	snapshot.Timestamp = snapshot.Timestamp.UTC()
+	if err := s.appendLine(s.ranksPath(kind), snapshot); err != nil {
+		return fmt.Errorf("unable to save ranks: %w", err)
+	}
+	return nil
+}
+
+func (s *DirStore) Ranks(kind RankKind, since time.Time, until time.Time) ([]RankSnapshot, error) {
+	if err := ValidateRankKind(kind); err != nil {
+		return nil, err
+	}
+	output := []RankSnapshot{}
+	err := s.readLines(s.ranksPath(kind), func(line []byte) error {
+		var snapshot RankSnapshot
+		if err := json.Unmarshal(line, &snapshot); err != nil {
+			return err
+		}
+		if !snapshot.Timestamp.Before(since) && snapshot.Timestamp.Before(until) {
+			output = append(output, snapshot)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read ranks: %w", err)
+	}
+	return output, nil
+}
+
+func (s *DirStore) LastRanks(kind RankKind, n int) ([]RankSnapshot, error) {
+	if err := ValidateRankKind(kind); err != nil {
+		return nil, err
+	}
+	output := []RankSnapshot{}
+	err := s.readLines(s.ranksPath(kind), func(line []byte) error {
+		var snapshot RankSnapshot
+		if err := json.Unmarshal(line, &snapshot); err != nil {
+			return err
+		}
+		output = append(output, snapshot)
+		if len(output) > n {
+			output = output[1:]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read ranks: %w", err)
+	}
+	return output, nil
+}
+
+func (s *DirStore) Close() error {
+	return nil
+}
+
+func (s *DirStore) watchlist() ([]string, error) {
+	output := []string{}
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, "watchlist.json"))
+	if os.IsNotExist(err) {
+		return output, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read watchlist: %w", err)
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("unable to read watchlist: %w", err)
+	}
+	return output, nil
+}
+
+// updateWatchlist applies update to the watched usernames and saves them sorted.
+func (s *DirStore) updateWatchlist(update func(usernames map[string]bool)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.watchlist()
+	if err != nil {
+		return err
+	}
+	usernames := map[string]bool{}
+	for _, username := range current {
+		usernames[username] = true
+	}
+	update(usernames)
+
+	output := make([]string, 0, len(usernames))
+	for username := range usernames {
+		output = append(output, username)
+	}
+	sort.Strings(output)
+	data, err := json.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("unable to save watchlist: %w", err)
+	}
+
+	// Write through a temporary file so a crash cannot leave half a watchlist
+	path := filepath.Join(s.dir, "watchlist.json")
+	if err := ioutil.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("unable to save watchlist: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("unable to save watchlist: %w", err)
+	}
+	return nil
+}
+
+// appendLine writes value as a JSON line at the end of a file.
+func (s *DirStore) appendLine(path string, value interface{}) error {
+	line, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// readLines calls fn with each line of a file, a missing file has no lines.
+func (s *DirStore) readLines(path string, fn func(line []byte) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// Rank lists make for long lines
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if err := fn(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *DirStore) ranksPath(kind RankKind) string {
+	return filepath.Join(s.dir, "ranks", string(kind)+".jsonl")
+}
+
+func (s *DirStore) statsPath(username string) string {
+	return filepath.Join(s.dir, "stats", username+".jsonl")
+}
@@ -0,0 +1,160 @@
+package history
+
+import (
+	"fmt"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// keyLayout formats point timestamps so they sort in the order they were taken.
+const keyLayout = "20060102T150405.000000000Z"
+
+// dayLayout is how days are named in daily deltas.
+const dayLayout = "2006-01-02"
+
+// usernameRegExp matches Nitro Type usernames, which also makes them safe to use as file names.
+var usernameRegExp = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+var (
+	ErrInvalidUsername = fmt.Errorf("invalid username")
+	ErrUnknownStore    = fmt.Errorf("unknown history store")
+)
+
+// StatPoint is the stats of a racer at one time.
+type StatPoint struct {
+	Timestamp    time.Time `json:"timestamp"`
+	RacesPlayed  int       `json:"racesPlayed"`
+	AvgSpeed     int       `json:"avgSpeed"`
+	HighestSpeed int       `json:"highestSpeed"`
+	Experience   int       `json:"experience"`
+	Level        int       `json:"level"`
+	Nitros       int       `json:"nitros"`
+	TotalCars    int       `json:"totalCars"`
+	ProfileViews int       `json:"profileViews"`
+}
+
+// StatDelta is how much each stat changed, AvgSpeed can go down.
+type StatDelta struct {
+	RacesPlayed  int `json:"racesPlayed"`
+	AvgSpeed     int `json:"avgSpeed"`
+	HighestSpeed int `json:"highestSpeed"`
+	Experience   int `json:"experience"`
+	Level        int `json:"level"`
+	Nitros       int `json:"nitros"`
+	TotalCars    int `json:"totalCars"`
+	ProfileViews int `json:"profileViews"`
+}
+
+// DailyDelta is the last stats recorded on a day and the change since the day before.
+// The change on the first day is measured from the first point of that day.
+type DailyDelta struct {
+	Date  string    `json:"date"`
+	Close StatPoint `json:"close"`
+	Delta StatDelta `json:"delta"`
+}
+
+// Store keeps the watchlist, the stats recorded for each watched racer and the rank lists of each scrape.
+// Usernames are case insensitive.
+type Store interface {
+	// Add records the stats of a racer.
+	Add(username string, point StatPoint) error
+
+	// Range returns the stats of a racer recorded from since up to but not including until, oldest first.
+	Range(username string, since time.Time, until time.Time) ([]StatPoint, error)
+
+	// Watchlist returns the watched usernames in order.
+	Watchlist() ([]string, error)
+
+	// Watch adds a racer to the watchlist, watching a racer twice is not an error.
+	Watch(username string) error
+
+	// Unwatch removes a racer from the watchlist, the stats recorded so far are kept.
+	Unwatch(username string) error
+
+	// AddRanks records a scraped rank list.
+	AddRanks(kind RankKind, snapshot RankSnapshot) error
+
+	// Ranks returns the rank lists scraped from since up to but not including until, oldest first.
+	Ranks(kind RankKind, since time.Time, until time.Time) ([]RankSnapshot, error)
+
+	// LastRanks returns up to the last n rank lists scraped, oldest first.
+	LastRanks(kind RankKind, n int) ([]RankSnapshot, error)
+
+	Close() error
+}
+
+// Open opens the store described by spec, either bolt:{file} or dir:{directory}.
+func Open(spec string) (Store, error) {
+	parts := strings.SplitN(spec, ":", 2)
+	if len(parts) == 2 && parts[1] != "" {
+		switch parts[0] {
+		case "bolt":
+			return OpenBolt(parts[1])
+		case "dir":
+			return OpenDir(parts[1])
+		}
+	}
+	return nil, fmt.Errorf("%w: %q (expected bolt:{file} or dir:{directory})", ErrUnknownStore, spec)
+}
+
+// NewStatPoint takes the tracked stats out of a racer profile.
+func NewStatPoint(player *nitrotype.NTPlayer, timestamp time.Time) StatPoint {
+	return StatPoint{
+		Timestamp:    timestamp.UTC(),
+		RacesPlayed:  player.RacesPlayed,
+		AvgSpeed:     player.AvgSpeed,
+		HighestSpeed: player.HighestSpeed,
+		Experience:   player.Experience,
+		Level:        player.Level,
+		Nitros:       player.Nitros,
+		TotalCars:    player.TotalCars,
+		ProfileViews: player.ProfileViews,
+	}
+}
+
+// Sub returns the change from before to p.
+func (p StatPoint) Sub(before StatPoint) StatDelta {
+	return StatDelta{
+		RacesPlayed:  p.RacesPlayed - before.RacesPlayed,
+		AvgSpeed:     p.AvgSpeed - before.AvgSpeed,
+		HighestSpeed: p.HighestSpeed - before.HighestSpeed,
+		Experience:   p.Experience - before.Experience,
+		Level:        p.Level - before.Level,
+		Nitros:       p.Nitros - before.Nitros,
+		TotalCars:    p.TotalCars - before.TotalCars,
+		ProfileViews: p.ProfileViews - before.ProfileViews,
+	}
+}
+
+// DailyDeltas groups points, oldest first, into UTC days. Days without points are skipped.
+func DailyDeltas(points []StatPoint) []DailyDelta {
+	output := []DailyDelta{}
+	for _, point := range points {
+		date := point.Timestamp.UTC().Format(dayLayout)
+		if len(output) > 0 && output[len(output)-1].Date == date {
+			output[len(output)-1].Close = point
+			continue
+		}
+		output = append(output, DailyDelta{Date: date, Close: point})
+	}
+
+	for i := range output {
+		previous := points[0]
+		if i > 0 {
+			previous = output[i-1].Close
+		}
+		output[i].Delta = output[i].Close.Sub(previous)
+	}
+	return output
+}
+
+// NormalizeUsername lowercases a username and checks it could be a Nitro Type username.
+func NormalizeUsername(username string) (string, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if !usernameRegExp.MatchString(username) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidUsername, username)
+	}
+	return username, nil
+}
@@ -0,0 +1,248 @@
+package history_test
+
+import (
+	"errors"
+	"nt-bootstrap-scraper/pkg/history"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// forEachStore runs a test against a new, empty store of every kind.
+func forEachStore(t *testing.T, test func(t *testing.T, store history.Store)) {
+	stores := []struct {
+		name string
+		open func(dir string) (history.Store, error)
+	}{
+		{name: "bolt", open: func(dir string) (history.Store, error) { return history.OpenBolt(filepath.Join(dir, "history.db")) }},
+		{name: "dir", open: func(dir string) (history.Store, error) { return history.OpenDir(dir) }},
+	}
+	for _, s := range stores {
+		t.Run(s.name, func(t *testing.T) {
+			store, err := s.open(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+			test(t, store)
+		})
+	}
+}
+
+func TestStoreRange(t *testing.T) {
+	base := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	forEachStore(t, func(t *testing.T, store history.Store) {
+		// Recorded out of order, with timestamps that only differ below a second
+		offsets := []time.Duration{
+			time.Hour,
+			0,
+			time.Nanosecond,
+			time.Millisecond,
+			2 * time.Hour,
+			-time.Nanosecond,
+		}
+		for i, offset := range offsets {
+			point := history.StatPoint{Timestamp: base.Add(offset), RacesPlayed: i}
+			if err := store.Add("speedy", point); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		zone := time.FixedZone("UTC-5", -5*60*60)
+		tests := []struct {
+			name  string
+			since time.Time
+			until time.Time
+			races []int
+		}{
+			{name: "everything", since: base.Add(-time.Hour), until: base.Add(3 * time.Hour), races: []int{5, 1, 2, 3, 0, 4}},
+			{name: "since is inclusive", since: base, until: base.Add(time.Hour), races: []int{1, 2, 3}},
+			{name: "until is exclusive", since: base.Add(-time.Nanosecond), until: base.Add(time.Millisecond), races: []int{5, 1, 2}},
+			{name: "nanosecond after since", since: base.Add(time.Nanosecond), until: base.Add(time.Hour + time.Nanosecond), races: []int{2, 3, 0}},
+			{name: "other time zone", since: base.In(zone), until: base.Add(time.Nanosecond).In(zone), races: []int{1}},
+			{name: "empty window", since: base, until: base, races: []int{}},
+			{name: "until before since", since: base.Add(time.Hour), until: base, races: []int{}},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				points, err := store.Range("speedy", test.since, test.until)
+				if err != nil {
+					t.Fatal(err)
+				}
+				races := []int{}
+				for _, point := range points {
+					races = append(races, point.RacesPlayed)
+				}
+				if !reflect.DeepEqual(races, test.races) {
+					t.Errorf("Range() = %v, want %v", races, test.races)
+				}
+			})
+		}
+
+		points, err := store.Range("nobody", base.Add(-time.Hour), base.Add(time.Hour))
+		if err != nil || len(points) != 0 {
+			t.Errorf("Range() of an unknown racer = %v, %v", points, err)
+		}
+	})
+}
+
+func TestStoreUsernames(t *testing.T) {
+	timestamp := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	forEachStore(t, func(t *testing.T, store history.Store) {
+		if err := store.Add(" Speedy ", history.StatPoint{Timestamp: timestamp, Level: 120}); err != nil {
+			t.Fatal(err)
+		}
+		points, err := store.Range("SPEEDY", timestamp, timestamp.Add(time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(points) != 1 || points[0].Level != 120 {
+			t.Errorf("Range(SPEEDY) = %+v, want the point added as Speedy", points)
+		}
+
+		for _, username := range []string{"", "two words", "../speedy", "speedy.json", "😀"} {
+			if err := store.Add(username, history.StatPoint{Timestamp: timestamp}); !errors.Is(err, history.ErrInvalidUsername) {
+				t.Errorf("Add(%q) error = %v, want ErrInvalidUsername", username, err)
+			}
+			if _, err := store.Range(username, timestamp, timestamp); !errors.Is(err, history.ErrInvalidUsername) {
+				t.Errorf("Range(%q) error = %v, want ErrInvalidUsername", username, err)
+			}
+			if err := store.Watch(username); !errors.Is(err, history.ErrInvalidUsername) {
+				t.Errorf("Watch(%q) error = %v, want ErrInvalidUsername", username, err)
+			}
+		}
+	})
+}
+
+func TestStoreWatchlist(t *testing.T) {
+	timestamp := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	forEachStore(t, func(t *testing.T, store history.Store) {
+		watchlist := func(want ...string) {
+			t.Helper()
+			got, err := store.Watchlist()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want == nil {
+				want = []string{}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Watchlist() = %q, want %q", got, want)
+			}
+		}
+
+		watchlist()
+		for _, username := range []string{"turtle", "Speedy", "speedy", "abc_123"} {
+			if err := store.Watch(username); err != nil {
+				t.Fatal(err)
+			}
+		}
+		watchlist("abc_123", "speedy", "turtle")
+
+		if err := store.Add("speedy", history.StatPoint{Timestamp: timestamp}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Unwatch("SPEEDY"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Unwatch("nobody"); err != nil {
+			t.Errorf("Unwatch() of an unwatched racer error = %v", err)
+		}
+		watchlist("abc_123", "turtle")
+
+		// Unwatching keeps the stats recorded so far
+		points, err := store.Range("speedy", timestamp, timestamp.Add(time.Second))
+		if err != nil || len(points) != 1 {
+			t.Errorf("Range() after Unwatch = %+v, %v", points, err)
+		}
+	})
+}
+
+func TestDailyDeltas(t *testing.T) {
+	at := func(value string, races int, level int) history.StatPoint {
+		timestamp, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return history.StatPoint{Timestamp: timestamp, RacesPlayed: races, Level: level}
+	}
+
+	tests := []struct {
+		name   string
+		points []history.StatPoint
+		want   []history.DailyDelta
+	}{
+		{
+			name: "no points",
+			want: []history.DailyDelta{},
+		},
+		{
+			name:   "single point",
+			points: []history.StatPoint{at("2022-01-01T12:00:00Z", 100, 10)},
+			want: []history.DailyDelta{
+				{Date: "2022-01-01", Close: at("2022-01-01T12:00:00Z", 100, 10)},
+			},
+		},
+		{
+			name: "across midnight",
+			points: []history.StatPoint{
+				at("2022-01-01T00:00:00Z", 100, 10),
+				at("2022-01-01T23:59:59Z", 150, 11),
+				at("2022-01-02T00:00:00Z", 151, 11),
+				at("2022-01-02T18:00:00Z", 190, 12),
+			},
+			want: []history.DailyDelta{
+				{Date: "2022-01-01", Close: at("2022-01-01T23:59:59Z", 150, 11), Delta: history.StatDelta{RacesPlayed: 50, Level: 1}},
+				{Date: "2022-01-02", Close: at("2022-01-02T18:00:00Z", 190, 12), Delta: history.StatDelta{RacesPlayed: 40, Level: 1}},
+			},
+		},
+		{
+			name: "skipped day",
+			points: []history.StatPoint{
+				at("2022-01-01T10:00:00Z", 100, 10),
+				at("2022-01-03T10:00:00Z", 130, 10),
+			},
+			want: []history.DailyDelta{
+				{Date: "2022-01-01", Close: at("2022-01-01T10:00:00Z", 100, 10)},
+				{Date: "2022-01-03", Close: at("2022-01-03T10:00:00Z", 130, 10), Delta: history.StatDelta{RacesPlayed: 30}},
+			},
+		},
+		{
+			// Days are UTC days whatever zone the points were recorded in
+			name: "other time zone",
+			points: []history.StatPoint{
+				at("2022-01-01T18:00:00-05:00", 90, 10),
+				at("2022-01-01T20:00:00-05:00", 100, 10),
+			},
+			want: []history.DailyDelta{
+				{Date: "2022-01-01", Close: at("2022-01-01T18:00:00-05:00", 90, 10)},
+				{Date: "2022-01-02", Close: at("2022-01-01T20:00:00-05:00", 100, 10), Delta: history.StatDelta{RacesPlayed: 10}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := history.DailyDeltas(test.points); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("DailyDeltas() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	for _, spec := range []string{"bolt:" + filepath.Join(dir, "history.db"), "dir:" + filepath.Join(dir, "history")} {
+		store, err := history.Open(spec)
+		if err != nil {
+			t.Errorf("Open(%q) error = %v", spec, err)
+			continue
+		}
+		store.Close()
+	}
+	for _, spec := range []string{"", "dir:", "redis:history"} {
+		if _, err := history.Open(spec); !errors.Is(err, history.ErrUnknownStore) {
+			t.Errorf("Open(%q) error = %v, want ErrUnknownStore", spec, err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package history
+
+import (
+	"fmt"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"sort"
+	"time"
+)
+
+// RankKind picks between the TOP_PLAYERS and TOP_TEAMS lists.
+type RankKind string
+
+const (
+	RankPlayers RankKind = "players"
+	RankTeams   RankKind = "teams"
+)
+
+var ErrInvalidRankKind = fmt.Errorf("unknown rank list")
+
+// RankSnapshot is a TOP_PLAYERS or TOP_TEAMS list as scraped at Timestamp.
+type RankSnapshot struct {
+	Timestamp time.Time            `json:"timestamp"`
+	Items     []nitrotype.RankItem `json:"items"`
+}
+
+// RankPosition is where an ID was ranked in one snapshot, Rank is nil when it was not listed.
+type RankPosition struct {
+	Timestamp time.Time `json:"timestamp"`
+	Rank      *int      `json:"rank"`
+	Position  *int      `json:"position"`
+}
+
+// RankEntry is an ID at a rank.
+type RankEntry struct {
+	ID   int `json:"id"`
+	Rank int `json:"rank"`
+}
+
+// RankChanges lists who joined and left a rank list between two snapshots.
+// Entrants have their new rank and drop outs their previous rank.
+type RankChanges struct {
+	From     time.Time   `json:"from"`
+	To       time.Time   `json:"to"`
+	Entrants []RankEntry `json:"entrants"`
+	DropOuts []RankEntry `json:"dropOuts"`
+}
+
+// RankMove is an ID listed at both ends of a window, Change is positive when it moved up.
+type RankMove struct {
+	ID     int `json:"id"`
+	From   int `json:"from"`
+	To     int `json:"to"`
+	Change int `json:"change"`
+}
+
+// RankMovers are the IDs that moved up and down the most between two snapshots, biggest move first.
+type RankMovers struct {
+	From time.Time  `json:"from"`
+	To   time.Time  `json:"to"`
+	Up   []RankMove `json:"up"`
+	Down []RankMove `json:"down"`
+}
+
+// ValidateRankKind returns ErrInvalidRankKind unless kind names a rank list.
+func ValidateRankKind(kind RankKind) error {
+	if kind != RankPlayers && kind != RankTeams {
+		return fmt.Errorf("%w: %q", ErrInvalidRankKind, kind)
+	}
+	return nil
+}
+
+// ranks maps the IDs of a snapshot to their rank, which is their place in the list.
+func (s RankSnapshot) ranks() map[int]int {
+	output := make(map[int]int, len(s.Items))
+	for i, item := range s.Items {
+		output[item.ID] = i + 1
+	}
+	return output
+}
+
+// RankMovement returns where an ID was ranked in each snapshot.
+func RankMovement(snapshots []RankSnapshot, id int) []RankPosition {
+	output := make([]RankPosition, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		position := RankPosition{Timestamp: snapshot.Timestamp}
+		for i, item := range snapshot.Items {
+			if item.ID != id {
+				continue
+			}
+			rank, value := i+1, item.Position
+			position.Rank, position.Position = &rank, &value
+			break
+		}
+		output = append(output, position)
+	}
+	return output
+}
+
+// CompareRanks lists the IDs that entered and dropped out of the list between two snapshots.
+func CompareRanks(from RankSnapshot, to RankSnapshot) RankChanges {
+	output := RankChanges{
+		From:     from.Timestamp,
+		To:       to.Timestamp,
+		Entrants: []RankEntry{},
+		DropOuts: []RankEntry{},
+	}
+	before, after := from.ranks(), to.ranks()
+	for _, item := range to.Items {
+		if _, ok := before[item.ID]; !ok {
+			output.Entrants = append(output.Entrants, RankEntry{ID: item.ID, Rank: after[item.ID]})
+		}
+	}
+	for _, item := range from.Items {
+		if _, ok := after[item.ID]; !ok {
+			output.DropOuts = append(output.DropOuts, RankEntry{ID: item.ID, Rank: before[item.ID]})
+		}
+	}
+	return output
+}
+
+// BiggestMovers returns up to limit IDs that moved up and down the most between two snapshots.
+// IDs missing from either snapshot are entrants or drop outs rather than movers.
+func BiggestMovers(from RankSnapshot, to RankSnapshot, limit int) RankMovers {
+	output := RankMovers{
+		From: from.Timestamp,
+		To:   to.Timestamp,
+		Up:   []RankMove{},
+		Down: []RankMove{},
+	}
+	before := from.ranks()
+	for i, item := range to.Items {
+		previous, ok := before[item.ID]
+		if !ok {
+			continue
+		}
+		move := RankMove{ID: item.ID, From: previous, To: i + 1, Change: previous - (i + 1)}
+		switch {
+		case move.Change > 0:
+			output.Up = append(output.Up, move)
+		case move.Change < 0:
+			output.Down = append(output.Down, move)
+		}
+	}
+
+	// Ties go to the better ranked ID
+	sort.SliceStable(output.Up, func(i, j int) bool {
+		return output.Up[i].Change > output.Up[j].Change
+	})
+	sort.SliceStable(output.Down, func(i, j int) bool {
+		return output.Down[i].Change < output.Down[j].Change
+	})
+	if limit > 0 && len(output.Up) > limit {
+		output.Up = output.Up[:limit]
+	}
+	if limit > 0 && len(output.Down) > limit {
+		output.Down = output.Down[:limit]
+	}
+	return output
+}
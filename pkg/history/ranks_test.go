@@ -0,0 +1,218 @@
+package history_test
+
+import (
+	"errors"
+	"nt-bootstrap-scraper/pkg/history"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// rankSnapshot lists ids in rank order, each with a position counting down from 1000.
+func rankSnapshot(timestamp time.Time, ids ...int) history.RankSnapshot {
+	items := make([]nitrotype.RankItem, 0, len(ids))
+	for i, id := range ids {
+		items = append(items, nitrotype.RankItem{Index: i, ID: id, Position: 1000 - i})
+	}
+	return history.RankSnapshot{Timestamp: timestamp, Items: items}
+}
+
+// rankTimes returns the hours after base of each snapshot.
+func rankTimes(base time.Time, snapshots []history.RankSnapshot) []int {
+	output := []int{}
+	for _, snapshot := range snapshots {
+		output = append(output, int(snapshot.Timestamp.Sub(base)/time.Hour))
+	}
+	return output
+}
+
+func TestStoreRanks(t *testing.T) {
+	base := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	forEachStore(t, func(t *testing.T, store history.Store) {
+		last, err := store.LastRanks(history.RankPlayers, 5)
+		if err != nil || len(last) != 0 {
+			t.Errorf("LastRanks() of an empty store = %+v, %v", last, err)
+		}
+
+		for hour := 0; hour < 4; hour++ {
+			if err := store.AddRanks(history.RankPlayers, rankSnapshot(base.Add(time.Duration(hour)*time.Hour), 1, 2, hour+3)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := store.AddRanks(history.RankTeams, rankSnapshot(base.Add(10*time.Hour), 7)); err != nil {
+			t.Fatal(err)
+		}
+
+		ranks, err := store.Ranks(history.RankPlayers, base.Add(time.Hour), base.Add(3*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rankTimes(base, ranks); !reflect.DeepEqual(got, []int{1, 2}) {
+			t.Errorf("Ranks() = hours %v, want [1 2]", got)
+		}
+		if !reflect.DeepEqual(ranks[0], rankSnapshot(base.Add(time.Hour), 1, 2, 4)) {
+			t.Errorf("Ranks()[0] = %+v", ranks[0])
+		}
+
+		tests := []struct {
+			n     int
+			hours []int
+		}{
+			{n: 1, hours: []int{3}},
+			{n: 3, hours: []int{1, 2, 3}},
+			{n: 4, hours: []int{0, 1, 2, 3}},
+			{n: 10, hours: []int{0, 1, 2, 3}},
+			{n: 0, hours: []int{}},
+			{n: -1, hours: []int{}},
+		}
+		for _, test := range tests {
+			last, err := store.LastRanks(history.RankPlayers, test.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rankTimes(base, last); !reflect.DeepEqual(got, test.hours) {
+				t.Errorf("LastRanks(%d) = hours %v, want %v", test.n, got, test.hours)
+			}
+		}
+
+		// Each rank list is kept apart
+		teams, err := store.LastRanks(history.RankTeams, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rankTimes(base, teams); !reflect.DeepEqual(got, []int{10}) || teams[0].Items[0].ID != 7 {
+			t.Errorf("LastRanks(teams) = %+v", teams)
+		}
+	})
+}
+
+func TestStoreRanksInvalidKind(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	forEachStore(t, func(t *testing.T, store history.Store) {
+		for _, kind := range []history.RankKind{"", "racers", "../players"} {
+			if err := store.AddRanks(kind, rankSnapshot(now, 1)); !errors.Is(err, history.ErrInvalidRankKind) {
+				t.Errorf("AddRanks(%q) error = %v, want ErrInvalidRankKind", kind, err)
+			}
+			if _, err := store.Ranks(kind, now, now.Add(time.Hour)); !errors.Is(err, history.ErrInvalidRankKind) {
+				t.Errorf("Ranks(%q) error = %v, want ErrInvalidRankKind", kind, err)
+			}
+			if _, err := store.LastRanks(kind, 1); !errors.Is(err, history.ErrInvalidRankKind) {
+				t.Errorf("LastRanks(%q) error = %v, want ErrInvalidRankKind", kind, err)
+			}
+		}
+	})
+}
+
+func TestCompareRanks(t *testing.T) {
+	from := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		from     []int
+		to       []int
+		entrants []history.RankEntry
+		dropOuts []history.RankEntry
+	}{
+		{
+			name:     "unchanged",
+			from:     []int{1, 2, 3},
+			to:       []int{3, 2, 1},
+			entrants: []history.RankEntry{},
+			dropOuts: []history.RankEntry{},
+		},
+		{
+			name:     "entering and leaving",
+			from:     []int{1, 2, 3, 4},
+			to:       []int{2, 5, 1, 6},
+			entrants: []history.RankEntry{{ID: 5, Rank: 2}, {ID: 6, Rank: 4}},
+			dropOuts: []history.RankEntry{{ID: 3, Rank: 3}, {ID: 4, Rank: 4}},
+		},
+		{
+			name:     "from an empty list",
+			to:       []int{1, 2},
+			entrants: []history.RankEntry{{ID: 1, Rank: 1}, {ID: 2, Rank: 2}},
+			dropOuts: []history.RankEntry{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes := history.CompareRanks(rankSnapshot(from, test.from...), rankSnapshot(to, test.to...))
+			if !changes.From.Equal(from) || !changes.To.Equal(to) {
+				t.Errorf("window = %s to %s", changes.From, changes.To)
+			}
+			if !reflect.DeepEqual(changes.Entrants, test.entrants) {
+				t.Errorf("Entrants = %+v, want %+v", changes.Entrants, test.entrants)
+			}
+			if !reflect.DeepEqual(changes.DropOuts, test.dropOuts) {
+				t.Errorf("DropOuts = %+v, want %+v", changes.DropOuts, test.dropOuts)
+			}
+		})
+	}
+}
+
+func TestBiggestMovers(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	// 5 climbs four places, 3 drops two, 1 and 2 drop one each, 4 leaves and 6 enters
+	from := rankSnapshot(now, 1, 2, 3, 4, 5)
+	to := rankSnapshot(now.Add(time.Hour), 5, 1, 2, 6, 3)
+
+	tests := []struct {
+		limit int
+		up    []history.RankMove
+		down  []history.RankMove
+	}{
+		{
+			limit: 0,
+			up:    []history.RankMove{{ID: 5, From: 5, To: 1, Change: 4}},
+			down:  []history.RankMove{{ID: 3, From: 3, To: 5, Change: -2}, {ID: 1, From: 1, To: 2, Change: -1}, {ID: 2, From: 2, To: 3, Change: -1}},
+		},
+		{
+			limit: 2,
+			up:    []history.RankMove{{ID: 5, From: 5, To: 1, Change: 4}},
+			down:  []history.RankMove{{ID: 3, From: 3, To: 5, Change: -2}, {ID: 1, From: 1, To: 2, Change: -1}},
+		},
+		{
+			limit: 10,
+			up:    []history.RankMove{{ID: 5, From: 5, To: 1, Change: 4}},
+			down:  []history.RankMove{{ID: 3, From: 3, To: 5, Change: -2}, {ID: 1, From: 1, To: 2, Change: -1}, {ID: 2, From: 2, To: 3, Change: -1}},
+		},
+	}
+	for _, test := range tests {
+		movers := history.BiggestMovers(from, to, test.limit)
+		if !reflect.DeepEqual(movers.Up, test.up) {
+			t.Errorf("BiggestMovers(%d).Up = %+v, want %+v", test.limit, movers.Up, test.up)
+		}
+		if !reflect.DeepEqual(movers.Down, test.down) {
+			t.Errorf("BiggestMovers(%d).Down = %+v, want %+v", test.limit, movers.Down, test.down)
+		}
+	}
+
+	if movers := history.BiggestMovers(from, from, 0); len(movers.Up) != 0 || len(movers.Down) != 0 {
+		t.Errorf("BiggestMovers() without changes = %+v", movers)
+	}
+}
+
+func TestRankMovement(t *testing.T) {
+	base := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	snapshots := []history.RankSnapshot{
+		rankSnapshot(base, 1, 2, 3),
+		rankSnapshot(base.Add(time.Hour), 1, 3),
+		rankSnapshot(base.Add(2*time.Hour), 2, 1),
+	}
+
+	movement := history.RankMovement(snapshots, 2)
+	if len(movement) != 3 {
+		t.Fatalf("RankMovement() = %+v", movement)
+	}
+	if movement[0].Rank == nil || *movement[0].Rank != 2 || *movement[0].Position != 999 {
+		t.Errorf("first = %+v, want rank 2", movement[0])
+	}
+	if movement[1].Rank != nil || movement[1].Position != nil {
+		t.Errorf("second = %+v, want unranked", movement[1])
+	}
+	if movement[2].Rank == nil || *movement[2].Rank != 1 || !movement[2].Timestamp.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("third = %+v, want rank 1", movement[2])
+	}
+}
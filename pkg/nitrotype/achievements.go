@@ -0,0 +1,219 @@
+package nitrotype
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// AchievementStatus is how far a racer is with an achievement.
+type AchievementStatus string
+
+const (
+	AchievementAchieved   AchievementStatus = "achieved"
+	AchievementInProgress AchievementStatus = "in-progress"
+	AchievementUnknown    AchievementStatus = "unknown-field"
+
+	// AchievementUnsupported is an achievement with a rule this package cannot check,
+	// such as a comparison it does not know or a value that is not a number.
+	AchievementUnsupported AchievementStatus = "unsupported-rule"
+)
+
+// AchievementGroupProgress is the progress of a racer through one achievement group.
+type AchievementGroupProgress struct {
+	Group        AchievementGroupItem  `json:"group"`
+	Achieved     int                   `json:"achieved"`
+	InProgress   int                   `json:"inProgress"`
+	Unknown      int                   `json:"unknown"`
+	Unsupported  int                   `json:"unsupported"`
+	Achievements []AchievementProgress `json:"achievements"`
+}
+
+// AchievementProgress is an achievement with its rules checked against a racer.
+// Progress is a percentage, the lowest of its rules since every rule has to pass.
+type AchievementProgress struct {
+	AchievementListItem
+	Status        AchievementStatus         `json:"status"`
+	Progress      float64                   `json:"progress"`
+	RuleProgress  []AchievementRuleProgress `json:"ruleProgress"`
+	UnknownFields []string                  `json:"unknownFields,omitempty"`
+}
+
+// AchievementRuleProgress is one rule checked against a racer, Actual is nil when the profile lacks the field.
+// Unsupported is set when the comparison or value of the rule could not be understood.
+type AchievementRuleProgress struct {
+	AchievementListItemRule
+	Actual      *float64 `json:"actual"`
+	Passed      bool     `json:"passed"`
+	Progress    float64  `json:"progress"`
+	Unsupported bool     `json:"unsupported,omitempty"`
+}
+
+// supportedComparisons are the rule comparisons evaluateRule can check.
+var supportedComparisons = map[string]bool{">=": true, ">": true, "<=": true, "<": true, "=": true, "==": true, "!=": true}
+
+// playerFields maps the fields achievement rules refer to onto the values in a racer profile.
+func playerFields(player *NTPlayer) map[string]float64 {
+	return map[string]float64{
+		"experience":     float64(player.Experience),
+		"level":          float64(player.Level),
+		"totalCars":      float64(player.TotalCars),
+		"nitros":         float64(player.Nitros),
+		"nitrosUsed":     float64(player.NitrosUsed),
+		"racesPlayed":    float64(player.RacesPlayed),
+		"longestSession": float64(player.LongestSession),
+		"avgSpeed":       float64(player.AvgSpeed),
+		"highestSpeed":   float64(player.HighestSpeed),
+		"profileViews":   float64(player.ProfileViews),
+		"createdStamp":   float64(player.CreatedStamp),
+		"carsOwned":      float64(len(player.Cars)),
+		"lootOwned":      float64(len(player.Loot)),
+	}
+}
+
+// EvaluateAchievements checks every active achievement in the catalogue against a racer.
+// Groups are returned in display order with achievements in catalogue order.
+// Achievements relying on fields the profile does not show, such as season stats, are reported as unknown,
+// and those with rules that cannot be checked as unsupported.
+func EvaluateAchievements(player *NTPlayer, globals *NTGlobals) []AchievementGroupProgress {
+	fields := playerFields(player)
+
+	groups := map[int]*AchievementGroupProgress{}
+	for _, group := range globals.Achievements.Group {
+		groups[group.AchievementGroupID] = &AchievementGroupProgress{
+			Group:        group,
+			Achievements: []AchievementProgress{},
+		}
+	}
+
+	for _, achievement := range globals.Achievements.List {
+		if achievement.Active == 0 {
+			continue
+		}
+		group, ok := groups[achievement.GID]
+		if !ok {
+			group = &AchievementGroupProgress{
+				Group:        AchievementGroupItem{AchievementGroupID: achievement.GID, ID: achievement.GID},
+				Achievements: []AchievementProgress{},
+			}
+			groups[achievement.GID] = group
+		}
+
+		item := evaluateAchievement(achievement, fields)
+		switch item.Status {
+		case AchievementAchieved:
+			group.Achieved++
+		case AchievementInProgress:
+			group.InProgress++
+		case AchievementUnsupported:
+			group.Unsupported++
+		default:
+			group.Unknown++
+		}
+		group.Achievements = append(group.Achievements, item)
+	}
+
+	output := make([]AchievementGroupProgress, 0, len(groups))
+	for _, group := range groups {
+		if len(group.Achievements) == 0 {
+			continue
+		}
+		output = append(output, *group)
+	}
+	sort.Slice(output, func(i, j int) bool {
+		if output[i].Group.DisplayOrder != output[j].Group.DisplayOrder {
+			return output[i].Group.DisplayOrder < output[j].Group.DisplayOrder
+		}
+		return output[i].Group.AchievementGroupID < output[j].Group.AchievementGroupID
+	})
+	return output
+}
+
+func evaluateAchievement(achievement AchievementListItem, fields map[string]float64) AchievementProgress {
+	output := AchievementProgress{
+		AchievementListItem: achievement,
+		Status:              AchievementAchieved,
+		Progress:            100,
+		RuleProgress:        make([]AchievementRuleProgress, 0, len(achievement.Rules)),
+	}
+
+	for _, rule := range achievement.Rules {
+		item := evaluateRule(rule, fields)
+		output.RuleProgress = append(output.RuleProgress, item)
+
+		if item.Unsupported {
+			output.Status = AchievementUnsupported
+			continue
+		}
+		if item.Actual == nil {
+			output.UnknownFields = append(output.UnknownFields, rule.Field)
+			if output.Status != AchievementUnsupported {
+				output.Status = AchievementUnknown
+			}
+			continue
+		}
+		if item.Progress < output.Progress {
+			output.Progress = item.Progress
+		}
+		if !item.Passed && output.Status == AchievementAchieved {
+			output.Status = AchievementInProgress
+		}
+	}
+	if output.Status == AchievementUnknown || output.Status == AchievementUnsupported {
+		output.Progress = 0
+	}
+	return output
+}
+
+// evaluateRule checks one rule against the racer fields.
+// An unsupported comparison or value is reported even when the field is missing, it could not be checked either way.
+func evaluateRule(rule AchievementListItemRule, fields map[string]float64) AchievementRuleProgress {
+	output := AchievementRuleProgress{AchievementListItemRule: rule}
+
+	want, ok := ruleValue(rule.Value)
+	if !ok || !supportedComparisons[rule.Comparison] {
+		output.Unsupported = true
+		return output
+	}
+	actual, ok := fields[rule.Field]
+	if !ok {
+		return output
+	}
+
+	switch rule.Comparison {
+	case ">=":
+		output.Passed = actual >= want
+	case ">":
+		output.Passed = actual > want
+	case "<=":
+		output.Passed = actual <= want
+	case "<":
+		output.Passed = actual < want
+	case "=", "==":
+		output.Passed = actual == want
+	case "!=":
+		output.Passed = actual != want
+	}
+	output.Actual = &actual
+
+	switch {
+	case output.Passed:
+		output.Progress = 100
+	case (rule.Comparison == ">=" || rule.Comparison == ">") && want > 0 && actual > 0:
+		// Only counting up has a meaningful part way mark
+		output.Progress = math.Min(math.Floor(actual/want*1000)/10, 99.9)
+	}
+	return output
+}
+
+// ruleValue reads a rule value, which is usually a number but sometimes a numeric string.
+func ruleValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		output, err := strconv.ParseFloat(v, 64)
+		return output, err == nil
+	}
+	return 0, false
+}
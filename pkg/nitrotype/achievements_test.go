@@ -0,0 +1,98 @@
+package nitrotype_test
+
+import (
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"testing"
+)
+
+func TestEvaluateAchievementRules(t *testing.T) {
+	player := &nitrotype.NTPlayer{Level: 50, RacesPlayed: 1000}
+
+	tests := []struct {
+		name        string
+		rule        nitrotype.AchievementListItemRule
+		status      nitrotype.AchievementStatus
+		passed      bool
+		progress    float64
+		unsupported bool
+	}{
+		{name: "at least reached", rule: rule("level", ">=", 50.0), status: nitrotype.AchievementAchieved, passed: true, progress: 100},
+		{name: "at least part way", rule: rule("level", ">=", 200.0), status: nitrotype.AchievementInProgress, progress: 25},
+		{name: "more than equal", rule: rule("level", ">", 50.0), status: nitrotype.AchievementInProgress, progress: 99.9},
+		{name: "at most", rule: rule("level", "<=", 50.0), status: nitrotype.AchievementAchieved, passed: true, progress: 100},
+		{name: "less than", rule: rule("level", "<", 50.0), status: nitrotype.AchievementInProgress},
+		{name: "equal", rule: rule("level", "=", 50.0), status: nitrotype.AchievementAchieved, passed: true, progress: 100},
+		{name: "double equal", rule: rule("level", "==", 49.0), status: nitrotype.AchievementInProgress},
+		{name: "not equal", rule: rule("level", "!=", 49.0), status: nitrotype.AchievementAchieved, passed: true, progress: 100},
+		{name: "numeric string value", rule: rule("racesPlayed", ">=", "4000"), status: nitrotype.AchievementInProgress, progress: 25},
+		{name: "missing field", rule: rule("seasonRaces", ">=", 10.0), status: nitrotype.AchievementUnknown},
+		{name: "unsupported comparison", rule: rule("level", "between", 50.0), status: nitrotype.AchievementUnsupported, unsupported: true},
+		{name: "unsupported value", rule: rule("level", ">=", "fifty"), status: nitrotype.AchievementUnsupported, unsupported: true},
+		{name: "unsupported with missing field", rule: rule("seasonRaces", "~", 1.0), status: nitrotype.AchievementUnsupported, unsupported: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var globals nitrotype.NTGlobals
+			globals.Achievements.List = []nitrotype.AchievementListItem{
+				{AchievementID: 1, GID: 1, Active: 1, Rules: []nitrotype.AchievementListItemRule{test.rule}},
+			}
+
+			groups := nitrotype.EvaluateAchievements(player, &globals)
+			if len(groups) != 1 || len(groups[0].Achievements) != 1 {
+				t.Fatalf("EvaluateAchievements() = %+v", groups)
+			}
+			achievement := groups[0].Achievements[0]
+			if achievement.Status != test.status {
+				t.Errorf("Status = %s, want %s", achievement.Status, test.status)
+			}
+			got := achievement.RuleProgress[0]
+			if got.Passed != test.passed || got.Progress != test.progress || got.Unsupported != test.unsupported {
+				t.Errorf("RuleProgress = %+v, want passed %v, progress %v, unsupported %v", got, test.passed, test.progress, test.unsupported)
+			}
+			if (got.Actual == nil) != (test.status == nitrotype.AchievementUnknown || test.unsupported) {
+				t.Errorf("Actual = %v with status %s", got.Actual, achievement.Status)
+			}
+		})
+	}
+}
+
+func TestEvaluateAchievementsGroups(t *testing.T) {
+	player := &nitrotype.NTPlayer{Level: 50, RacesPlayed: 1000}
+
+	var globals nitrotype.NTGlobals
+	globals.Achievements.Group = []nitrotype.AchievementGroupItem{
+		{AchievementGroupID: 1, Name: "Racing", DisplayOrder: 2},
+		{AchievementGroupID: 2, Name: "Seasons", DisplayOrder: 1},
+		{AchievementGroupID: 3, Name: "Empty", DisplayOrder: 0},
+	}
+	globals.Achievements.List = []nitrotype.AchievementListItem{
+		{AchievementID: 1, GID: 1, Active: 1, Rules: []nitrotype.AchievementListItemRule{rule("level", ">=", 10.0), rule("racesPlayed", ">=", 2000.0)}},
+		{AchievementID: 2, GID: 1, Active: 1, Rules: []nitrotype.AchievementListItemRule{rule("level", ">=", 10.0)}},
+		{AchievementID: 3, GID: 1, Active: 0, Rules: []nitrotype.AchievementListItemRule{rule("level", ">=", 10.0)}},
+		{AchievementID: 4, GID: 2, Active: 1, Rules: []nitrotype.AchievementListItemRule{rule("seasonRaces", ">=", 10.0), rule("level", ">=", 10.0)}},
+		{AchievementID: 5, GID: 2, Active: 1, Rules: []nitrotype.AchievementListItemRule{rule("seasonRaces", ">=", 10.0), rule("level", "in", 10.0)}},
+	}
+
+	groups := nitrotype.EvaluateAchievements(player, &globals)
+	if len(groups) != 2 || groups[0].Group.Name != "Seasons" || groups[1].Group.Name != "Racing" {
+		t.Fatalf("EvaluateAchievements() = %+v, want Seasons then Racing", groups)
+	}
+
+	seasons, racing := groups[0], groups[1]
+	if racing.Achieved != 1 || racing.InProgress != 1 || len(racing.Achievements) != 2 {
+		t.Errorf("Racing = %+v", racing)
+	}
+	if first := racing.Achievements[0]; first.Progress != 50 {
+		t.Errorf("Progress = %v, want the lowest rule progress", first.Progress)
+	}
+	if seasons.Unknown != 1 || seasons.Unsupported != 1 {
+		t.Errorf("Seasons = %+v, want one unknown and one unsupported", seasons)
+	}
+	if unknown := seasons.Achievements[0]; unknown.Progress != 0 || len(unknown.UnknownFields) != 1 || unknown.UnknownFields[0] != "seasonRaces" {
+		t.Errorf("unknown achievement = %+v", unknown)
+	}
+}
+
+func rule(field string, comparison string, value interface{}) nitrotype.AchievementListItemRule {
+	return nitrotype.AchievementListItemRule{Field: field, Comparison: comparison, Value: value}
+}
@@ -0,0 +1,94 @@
+package nitrotype
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// PlayersOptions configures GetPlayersData.
+type PlayersOptions struct {
+	// Fetcher collects each profile, defaults to a ChromeFetcher sharing one browser for the whole batch.
+	Fetcher Fetcher
+
+	// Concurrency is how many profiles are fetched at once, defaults to DefaultMaxTabs.
+	Concurrency int
+}
+
+// PlayerResult is the outcome of looking up one racer in a batch, either Player or Err is set.
+type PlayerResult struct {
+	Username string
+	Player   *NTPlayer
+	Err      error
+}
+
+func (r PlayerResult) MarshalJSON() ([]byte, error) {
+	output := struct {
+		Username string    `json:"username"`
+		Player   *NTPlayer `json:"player,omitempty"`
+		Error    string    `json:"error,omitempty"`
+	}{
+		Username: r.Username,
+		Player:   r.Player,
+	}
+	if r.Err != nil {
+		output.Error = r.Err.Error()
+	}
+	return json.Marshal(output)
+}
+
+// GetPlayersData fetches the RACER_INFO data of several racers, a few at a time.
+// Results are in the same order as usernames and a failed lookup does not stop the others.
+// Lookups not yet started when ctx is done fail with the context error.
+func GetPlayersData(ctx context.Context, usernames []string, opts PlayersOptions) []PlayerResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultMaxTabs
+	}
+	fetcher := opts.Fetcher
+	if fetcher == nil {
+		browser := NewBrowser(concurrency)
+		defer browser.Close()
+		fetcher = ChromeFetcher{Browser: browser}
+	}
+
+	// Look up repeated usernames once
+	indexes := map[string][]int{}
+	var unique []string
+	for i, username := range usernames {
+		if _, ok := indexes[username]; !ok {
+			unique = append(unique, username)
+		}
+		indexes[username] = append(indexes[username], i)
+	}
+
+	output := make([]PlayerResult, len(usernames))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, username := range unique {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			for _, i := range indexes[username] {
+				output[i] = PlayerResult{Username: username, Err: ctx.Err()}
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(username string) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			result := PlayerResult{Username: username}
+			result.Player, result.Err = fetcher.GetPlayerData(ctx, username)
+			for _, i := range indexes[username] {
+				output[i] = result
+			}
+		}(username)
+	}
+	wg.Wait()
+
+	return output
+}
@@ -0,0 +1,161 @@
+package nitrotype
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var (
+	BootstrapGlobalKeyRegExp  = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+	ErrBootstrapEntryNotFound = fmt.Errorf("entry not found")
+)
+
+// BootstrapEntryError is returned when a named entry of bootstrap.js is missing or cannot be decoded.
+type BootstrapEntryError struct {
+	Key string
+	Err error
+}
+
+func (e *BootstrapEntryError) Error() string {
+	return fmt.Sprintf("bootstrap entry %q: %s", e.Key, e.Err)
+}
+
+func (e *BootstrapEntryError) Unwrap() error {
+	return e.Err
+}
+
+// BootstrapScript holds the ["KEY", value] entries that bootstrap.js assigns onto NTGLOBALS.
+type BootstrapScript struct {
+	keys    []string
+	entries map[string]*JSValue
+	failed  map[string]error
+}
+
+// ParseBootstrapScript tokenizes bootstrap.js and decodes every ["KEY", value] pair it contains.
+// Entries whose value is not a literal are remembered so Entry can explain why they failed.
+func ParseBootstrapScript(src []byte) (*BootstrapScript, error) {
+	tokens, err := tokenizeJS(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("unable to tokenize bootstrap.js: %w", err)
+	}
+
+	output := &BootstrapScript{
+		entries: map[string]*JSValue{},
+		failed:  map[string]error{},
+	}
+	p := &jsValueParser{tokens: tokens}
+	for i := 0; i+3 < len(tokens); i++ {
+		if tokens[i].Kind != jsTokenPunct || tokens[i].Text != "[" ||
+			tokens[i+1].Kind != jsTokenString ||
+			tokens[i+2].Kind != jsTokenPunct || tokens[i+2].Text != "," {
+			continue
+		}
+		key := tokens[i+1].Text
+
+		p.pos = i + 3
+		value, err := p.parseValue()
+		if err == nil && !p.isPunct("]") {
+			err = unexpectedToken(p.peek(), `"]"`)
+		}
+		if err != nil {
+			if _, ok := output.entries[key]; !ok {
+				output.failed[key] = err
+			}
+			continue
+		}
+
+		// Skip over the value so nested arrays are not mistaken for entries
+		i = p.pos
+
+		// The first successful match is the NTGLOBALS entry, later arrays reusing the key do not replace it
+		if _, ok := output.entries[key]; ok {
+			continue
+		}
+		output.keys = append(output.keys, key)
+		output.entries[key] = value
+		delete(output.failed, key)
+	}
+
+	if len(output.entries) == 0 {
+		return nil, &ScrapeError{Kind: ErrNTGlobalsMissing, Err: fmt.Errorf("no entries in bootstrap.js")}
+	}
+
+	return output, nil
+}
+
+// Keys returns the entry names in the order they appear.
+func (b *BootstrapScript) Keys() []string {
+	return append([]string(nil), b.keys...)
+}
+
+// Entry returns the decoded value of a named entry.
+func (b *BootstrapScript) Entry(key string) (*JSValue, error) {
+	if value, ok := b.entries[key]; ok {
+		return value, nil
+	}
+	if err, ok := b.failed[key]; ok {
+		return nil, &BootstrapEntryError{Key: key, Err: err}
+	}
+	return nil, &BootstrapEntryError{Key: key, Err: ErrBootstrapEntryNotFound}
+}
+
+// Globals returns the NTGLOBALS entries as a map, TOP_PLAYERS is left as found in the script.
+func (b *BootstrapScript) Globals() NTGlobalsLegacy {
+	output := NTGlobalsLegacy{}
+	for _, key := range b.keys {
+		if !BootstrapGlobalKeyRegExp.MatchString(key) {
+			continue
+		}
+		output[key] = b.entries[key].Interface()
+	}
+	return output
+}
+
+// TopPlayers returns the Top Players and Teams in the order they are listed in TOP_PLAYERS.
+func (b *BootstrapScript) TopPlayers() ([]RankItem, []RankItem, error) {
+	entry, err := b.Entry("TOP_PLAYERS")
+	if err != nil {
+		return nil, nil, err
+	}
+	if entry.Kind != JSObject {
+		return nil, nil, &BootstrapEntryError{Key: "TOP_PLAYERS", Err: fmt.Errorf("expected object, found %s", entry.Kind)}
+	}
+	topPlayers, err := parseRankItems(entry, "users")
+	if err != nil {
+		return nil, nil, &BootstrapEntryError{Key: "TOP_PLAYERS", Err: err}
+	}
+	topTeams, err := parseRankItems(entry, "teams")
+	if err != nil {
+		return nil, nil, &BootstrapEntryError{Key: "TOP_PLAYERS", Err: err}
+	}
+	return topPlayers, topTeams, nil
+}
+
+// parseRankItems reads an {"id": position} object keeping its order.
+func parseRankItems(entry *JSValue, field string) ([]RankItem, error) {
+	value := entry.Get(field)
+	if value == nil {
+		return nil, fmt.Errorf("%s: not found", field)
+	}
+	if value.Kind != JSObject {
+		return nil, fmt.Errorf("%s: expected object, found %s", field, value.Kind)
+	}
+
+	output := make([]RankItem, 0, len(value.Object))
+	for i, prop := range value.Object {
+		id, err := strconv.Atoi(prop.Key)
+		if err != nil {
+			return nil, fmt.Errorf("%s (row: %d): id %q is not a number", field, i, prop.Key)
+		}
+		if prop.Value.Kind != JSNumber || prop.Value.Number != float64(int(prop.Value.Number)) {
+			return nil, fmt.Errorf("%s (row: %d): position for %d is not a whole number", field, i, id)
+		}
+		output = append(output, RankItem{
+			Index:    i + 1,
+			ID:       id,
+			Position: int(prop.Value.Number),
+		})
+	}
+	return output, nil
+}
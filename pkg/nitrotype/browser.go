@@ -0,0 +1,285 @@
+package nitrotype
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// DefaultMaxTabs is the number of tabs a Browser will open at once when not configured.
+const DefaultMaxTabs = 4
+
+// Browser is a long-lived headless Chrome that hands out tabs from a bounded pool.
+// The browser is started on first use and restarted if it crashes.
+type Browser struct {
+	maxTabs   int
+	allocOpts []chromedp.ExecAllocatorOption
+	tabs      chan struct{}
+
+	mu            sync.Mutex
+	closed        bool
+	allocCancel   context.CancelFunc
+	browserCtx    context.Context
+	browserCancel context.CancelFunc
+
+	waiting    int64
+	active     int64
+	tabsOpened int64
+	launches   int64
+	restarts   int64
+}
+
+// BrowserStats is a snapshot of the Browser tab pool.
+type BrowserStats struct {
+	Running     bool  `json:"running"`
+	MaxTabs     int   `json:"maxTabs"`
+	ActiveTabs  int64 `json:"activeTabs"`
+	WaitingTabs int64 `json:"waitingTabs"`
+	TabsOpened  int64 `json:"tabsOpened"`
+	Launches    int64 `json:"launches"`
+	Restarts    int64 `json:"restarts"`
+}
+
+// NewBrowser creates a shared browser allowing up to maxTabs tabs at once.
+// Extra allocator options are appended to the defaults used by the scraper.
+func NewBrowser(maxTabs int, opts ...chromedp.ExecAllocatorOption) *Browser {
+	if maxTabs <= 0 {
+		maxTabs = DefaultMaxTabs
+	}
+	return &Browser{
+		maxTabs:   maxTabs,
+		allocOpts: append(allocatorOptions(), opts...),
+		tabs:      make(chan struct{}, maxTabs),
+	}
+}
+
+// NewTab waits for a free slot in the pool and opens a new tab.
+// The returned cancel function closes the tab and must always be called.
+// The tab is also closed when ctx is done.
+// When ctx carries a proxy from WithProxy the tab gets its own browser context using that proxy.
+func (b *Browser) NewTab(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	atomic.AddInt64(&b.waiting, 1)
+	select {
+	case b.tabs <- struct{}{}:
+		atomic.AddInt64(&b.waiting, -1)
+	case <-ctx.Done():
+		atomic.AddInt64(&b.waiting, -1)
+		return nil, nil, ctx.Err()
+	}
+	release := func() {
+		<-b.tabs
+	}
+
+	browserCtx, err := b.browser()
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	tabCtx, tabCancel, err := newTabContext(ctx, browserCtx, proxyFromContext(ctx))
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	atomic.AddInt64(&b.tabsOpened, 1)
+	atomic.AddInt64(&b.active, 1)
+
+	// Close the tab early when the caller gives up
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			tabCancel()
+		case <-stop:
+		}
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			close(stop)
+			tabCancel()
+			atomic.AddInt64(&b.active, -1)
+			release()
+		})
+	}
+
+	return tabCtx, cancel, nil
+}
+
+// Stats returns the current state of the tab pool.
+func (b *Browser) Stats() BrowserStats {
+	b.mu.Lock()
+	running := b.browserCtx != nil && b.browserCtx.Err() == nil
+	b.mu.Unlock()
+
+	return BrowserStats{
+		Running:     running,
+		MaxTabs:     b.maxTabs,
+		ActiveTabs:  atomic.LoadInt64(&b.active),
+		WaitingTabs: atomic.LoadInt64(&b.waiting),
+		TabsOpened:  atomic.LoadInt64(&b.tabsOpened),
+		Launches:    atomic.LoadInt64(&b.launches),
+		Restarts:    atomic.LoadInt64(&b.restarts),
+	}
+}
+
+// Close shuts down Chrome. Tabs requested afterwards fail with ErrBrowserClosed.
+func (b *Browser) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.shutdown()
+	return nil
+}
+
+// browser returns the root browser context, starting Chrome if it is not running.
+func (b *Browser) browser() (context.Context, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBrowserClosed
+	}
+	if b.browserCtx != nil && b.browserCtx.Err() == nil {
+		return b.browserCtx, nil
+	}
+
+	// Chrome went away (crash or lost connection), start again
+	if b.browserCtx != nil {
+		atomic.AddInt64(&b.restarts, 1)
+		b.shutdown()
+	}
+
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), b.allocOpts...)
+	browserCtx, browserCancel := chromedp.NewContext(
+		allocCtx,
+		chromedp.WithLogf(log.Printf),
+	)
+	if err := chromedp.Run(browserCtx); err != nil {
+		browserCancel()
+		allocCancel()
+		return nil, &ScrapeError{Kind: ErrBrowserLaunch, Err: err}
+	}
+	atomic.AddInt64(&b.launches, 1)
+
+	b.allocCancel = allocCancel
+	b.browserCtx = browserCtx
+	b.browserCancel = browserCancel
+	return browserCtx, nil
+}
+
+// shutdown stops the running browser, the caller must hold the lock.
+func (b *Browser) shutdown() {
+	if b.browserCancel != nil {
+		b.browserCancel()
+	}
+	if b.allocCancel != nil {
+		b.allocCancel()
+	}
+	b.browserCtx = nil
+	b.browserCancel = nil
+	b.allocCancel = nil
+}
+
+// newTabContext opens a tab in the browser, in a separate browser context when going through a proxy.
+func newTabContext(ctx context.Context, browserCtx context.Context, proxyURL *url.URL) (context.Context, context.CancelFunc, error) {
+	if proxyURL == nil {
+		tabCtx, tabCancel := chromedp.NewContext(browserCtx)
+		if err := chromedp.Run(tabCtx); err != nil {
+			tabCancel()
+			return nil, nil, fmt.Errorf("unable to open tab: %w", err)
+		}
+		return tabCtx, tabCancel, nil
+	}
+
+	proxyServer, err := chromeProxyServer(proxyURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	executor := cdp.WithExecutor(ctx, chromedp.FromContext(browserCtx).Browser)
+	contextID, err := target.CreateBrowserContext().WithProxyServer(proxyServer).Do(executor)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create browser context for proxy: %w", err)
+	}
+	disposeContext := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		target.DisposeBrowserContext(contextID).Do(cdp.WithExecutor(ctx, chromedp.FromContext(browserCtx).Browser))
+	}
+
+	targetID, err := target.CreateTarget("about:blank").WithBrowserContextID(contextID).Do(executor)
+	if err != nil {
+		disposeContext()
+		return nil, nil, fmt.Errorf("unable to open tab: %w", err)
+	}
+	tabCtx, tabCancel := chromedp.NewContext(browserCtx, chromedp.WithTargetID(targetID))
+	cancel := func() {
+		tabCancel()
+		disposeContext()
+	}
+	if err := chromedp.Run(tabCtx); err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("unable to open tab: %w", err)
+	}
+	if err := enableProxyAuth(tabCtx, proxyURL); err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("unable to set up proxy authentication: %w", err)
+	}
+	return tabCtx, cancel, nil
+}
+
+// allocatorOptions are the Chrome flags used for scraping.
+func allocatorOptions() []chromedp.ExecAllocatorOption {
+	return []chromedp.ExecAllocatorOption{
+		chromedp.UserAgent(UserAgent),
+		chromedp.NoFirstRun,
+		chromedp.NoDefaultBrowserCheck,
+		chromedp.NoSandbox,
+		chromedp.Headless,
+		chromedp.DisableGPU,
+	}
+}
+
+// newStandaloneTab starts a throwaway Chrome for a single scrape, using the proxy from ctx if there is one.
+func newStandaloneTab(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	opts := allocatorOptions()
+	proxyURL := proxyFromContext(ctx)
+	if proxyURL != nil {
+		proxyServer, err := chromeProxyServer(proxyURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, chromedp.ProxyServer(proxyServer))
+	}
+
+	ctx, allocCancel := chromedp.NewExecAllocator(ctx, opts...)
+	ctx, tabCancel := chromedp.NewContext(
+		ctx,
+		chromedp.WithLogf(log.Printf),
+	)
+	cancel := func() {
+		tabCancel()
+		allocCancel()
+	}
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		return nil, nil, &ScrapeError{Kind: ErrBrowserLaunch, Err: err}
+	}
+	if proxyURL != nil {
+		if err := enableProxyAuth(ctx, proxyURL); err != nil {
+			cancel()
+			return nil, nil, fmt.Errorf("unable to set up proxy authentication: %w", err)
+		}
+	}
+	return ctx, cancel, nil
+}
@@ -0,0 +1,104 @@
+package nitrotype
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// CashTransfer describes a cash gift a racer wants to send.
+type CashTransfer struct {
+	SenderLevel  int   `json:"senderLevel"`
+	CreatedStamp int64 `json:"createdStamp"`
+	Amount       int64 `json:"amount"`
+	SentThisWeek int64 `json:"sentThisWeek"`
+
+	// Team uses the weekly cap for sending to team members.
+	Team bool `json:"team"`
+}
+
+// CashTransferQuote is whether a transfer is allowed under CASH_SENDING and what it costs.
+// Reasons explains every rule the transfer breaks.
+// AccountAgeDays is nil when the account creation time is not known, the account age is not checked then.
+// RemainingAllowance and RemainingAfter are nil when there is no weekly limit.
+type CashTransferQuote struct {
+	CashTransfer
+	Allowed            bool     `json:"allowed"`
+	Reasons            []string `json:"reasons"`
+	Fee                int64    `json:"fee"`
+	Received           int64    `json:"received"`
+	AccountAgeDays     *int     `json:"accountAgeDays"`
+	WeeklyLimit        int64    `json:"weeklyLimit"`
+	RemainingAllowance *int64   `json:"remainingAllowance"`
+	RemainingAfter     *int64   `json:"remainingAfter"`
+}
+
+// Fee returns the fee taken from an amount.
+// FeePercent is read as a fraction of the amount, 0.05 takes 5%, values that look like whole percents are not rescaled.
+// The fee is rounded to the nearest dollar and comes out of the amount the recipient gets.
+func (c CashSending) Fee(amount int64) int64 {
+	if amount <= 0 {
+		return 0
+	}
+	return int64(math.Round(float64(amount) * c.FeePercent))
+}
+
+// WeeklyLimit returns the most cash that can be sent in a week, zero means there is no limit.
+func (c CashSending) WeeklyLimit(team bool) int64 {
+	if team {
+		return c.MaxPerWeekTeams
+	}
+	return c.MaxPerWeek
+}
+
+// Quote checks a transfer against the cash sending rules.
+// MinAccountAge is read as days, limits of zero are not enforced.
+func (c CashSending) Quote(transfer CashTransfer, now time.Time) CashTransferQuote {
+	output := CashTransferQuote{
+		CashTransfer: transfer,
+		Reasons:      []string{},
+		Fee:          c.Fee(transfer.Amount),
+		WeeklyLimit:  c.WeeklyLimit(transfer.Team),
+	}
+	output.Received = transfer.Amount - output.Fee
+	if transfer.CreatedStamp > 0 {
+		days := 0
+		if created := time.Unix(transfer.CreatedStamp, 0); now.After(created) {
+			days = int(now.Sub(created).Hours() / 24)
+		}
+		output.AccountAgeDays = &days
+	}
+	if output.WeeklyLimit > 0 {
+		remaining := output.WeeklyLimit - transfer.SentThisWeek
+		if remaining < 0 {
+			remaining = 0
+		}
+		output.RemainingAllowance = &remaining
+	}
+
+	if transfer.SenderLevel < c.MinLevel {
+		output.Reasons = append(output.Reasons, fmt.Sprintf("sender must be at least level %d", c.MinLevel))
+	}
+	if output.AccountAgeDays != nil && *output.AccountAgeDays < c.MinAccountAge {
+		output.Reasons = append(output.Reasons, fmt.Sprintf("sender account must be at least %d days old", c.MinAccountAge))
+	}
+	if transfer.Amount < c.Minimum {
+		output.Reasons = append(output.Reasons, fmt.Sprintf("amount must be at least $%d", c.Minimum))
+	}
+	if c.Maximum > 0 && transfer.Amount > c.Maximum {
+		output.Reasons = append(output.Reasons, fmt.Sprintf("amount must be at most $%d", c.Maximum))
+	}
+	if output.RemainingAllowance != nil && transfer.Amount > *output.RemainingAllowance {
+		output.Reasons = append(output.Reasons, fmt.Sprintf("amount is over the $%d left of the weekly limit", *output.RemainingAllowance))
+	}
+
+	output.Allowed = len(output.Reasons) == 0
+	if output.RemainingAllowance != nil {
+		remaining := *output.RemainingAllowance
+		if output.Allowed {
+			remaining -= transfer.Amount
+		}
+		output.RemainingAfter = &remaining
+	}
+	return output
+}
@@ -0,0 +1,195 @@
+package nitrotype_test
+
+import (
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCashSendingFee(t *testing.T) {
+	tests := []struct {
+		name       string
+		feePercent float64
+		amount     int64
+		want       int64
+	}{
+		{name: "fraction", feePercent: 0.05, amount: 10000, want: 500},
+		{name: "one percent", feePercent: 0.01, amount: 10000, want: 100},
+		{name: "rounds to nearest dollar", feePercent: 0.05, amount: 1010, want: 51},
+		{name: "no fee", feePercent: 0, amount: 10000, want: 0},
+		{name: "negative amount", feePercent: 0.05, amount: -100, want: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules := nitrotype.CashSending{FeePercent: test.feePercent}
+			if got := rules.Fee(test.amount); got != test.want {
+				t.Errorf("Fee(%d) = %d, want %d", test.amount, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCashSendingQuote(t *testing.T) {
+	now := time.Unix(1640995200, 0)
+	day := int64(24 * 60 * 60)
+	rules := nitrotype.CashSending{
+		MinLevel:        20,
+		Minimum:         1000,
+		Maximum:         100000,
+		MaxPerWeek:      200000,
+		MaxPerWeekTeams: 400000,
+		FeePercent:      0.05,
+		MinAccountAge:   30,
+	}
+	unlimited := rules
+	unlimited.Maximum = 0
+	unlimited.MaxPerWeek = 0
+	unlimited.MaxPerWeekTeams = 0
+
+	valid := nitrotype.CashTransfer{
+		SenderLevel:  50,
+		CreatedStamp: now.Unix() - 60*day,
+		Amount:       10000,
+	}
+
+	tests := []struct {
+		name      string
+		rules     nitrotype.CashSending
+		transfer  func(nitrotype.CashTransfer) nitrotype.CashTransfer
+		reasons   []string
+		age       *int
+		remaining *int64
+	}{
+		{
+			name:      "allowed",
+			rules:     rules,
+			transfer:  func(t nitrotype.CashTransfer) nitrotype.CashTransfer { return t },
+			reasons:   []string{},
+			age:       intPointer(60),
+			remaining: int64Pointer(190000),
+		},
+		{
+			name:  "low level and new account",
+			rules: rules,
+			transfer: func(t nitrotype.CashTransfer) nitrotype.CashTransfer {
+				t.SenderLevel = 5
+				t.CreatedStamp = now.Unix() - 10*day
+				return t
+			},
+			reasons:   []string{"sender must be at least level 20", "sender account must be at least 30 days old"},
+			age:       intPointer(10),
+			remaining: int64Pointer(200000),
+		},
+		{
+			name:  "unknown account age",
+			rules: rules,
+			transfer: func(t nitrotype.CashTransfer) nitrotype.CashTransfer {
+				t.CreatedStamp = 0
+				return t
+			},
+			reasons:   []string{},
+			remaining: int64Pointer(190000),
+		},
+		{
+			name:  "amount limits",
+			rules: rules,
+			transfer: func(t nitrotype.CashTransfer) nitrotype.CashTransfer {
+				t.Amount = 150000
+				t.SentThisWeek = 100000
+				return t
+			},
+			reasons:   []string{"amount must be at most $100000", "amount is over the $100000 left of the weekly limit"},
+			age:       intPointer(60),
+			remaining: int64Pointer(100000),
+		},
+		{
+			name:  "below minimum",
+			rules: rules,
+			transfer: func(t nitrotype.CashTransfer) nitrotype.CashTransfer {
+				t.Amount = 500
+				return t
+			},
+			reasons:   []string{"amount must be at least $1000"},
+			age:       intPointer(60),
+			remaining: int64Pointer(200000),
+		},
+		{
+			name:  "team weekly limit",
+			rules: rules,
+			transfer: func(t nitrotype.CashTransfer) nitrotype.CashTransfer {
+				t.SentThisWeek = 250000
+				t.Team = true
+				return t
+			},
+			reasons:   []string{},
+			age:       intPointer(60),
+			remaining: int64Pointer(140000),
+		},
+		{
+			name:  "weekly limit used up",
+			rules: rules,
+			transfer: func(t nitrotype.CashTransfer) nitrotype.CashTransfer {
+				t.SentThisWeek = 250000
+				return t
+			},
+			reasons:   []string{"amount is over the $0 left of the weekly limit"},
+			age:       intPointer(60),
+			remaining: int64Pointer(0),
+		},
+		{
+			name:  "no limits",
+			rules: unlimited,
+			transfer: func(t nitrotype.CashTransfer) nitrotype.CashTransfer {
+				t.Amount = 5000000
+				t.SentThisWeek = 10000000
+				return t
+			},
+			reasons: []string{},
+			age:     intPointer(60),
+		},
+		{
+			name:  "no team limit",
+			rules: unlimited,
+			transfer: func(t nitrotype.CashTransfer) nitrotype.CashTransfer {
+				t.Team = true
+				return t
+			},
+			reasons: []string{},
+			age:     intPointer(60),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			quote := test.rules.Quote(test.transfer(valid), now)
+			if !reflect.DeepEqual(quote.Reasons, test.reasons) {
+				t.Errorf("Reasons = %q, want %q", quote.Reasons, test.reasons)
+			}
+			if quote.Allowed != (len(test.reasons) == 0) {
+				t.Errorf("Allowed = %v with reasons %q", quote.Allowed, quote.Reasons)
+			}
+			if !reflect.DeepEqual(quote.AccountAgeDays, test.age) {
+				t.Errorf("AccountAgeDays = %v, want %v", formatPointer(quote.AccountAgeDays), formatPointer(test.age))
+			}
+			if !reflect.DeepEqual(quote.RemainingAfter, test.remaining) {
+				t.Errorf("RemainingAfter = %v, want %v", formatPointer(quote.RemainingAfter), formatPointer(test.remaining))
+			}
+		})
+	}
+}
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func int64Pointer(value int64) *int64 {
+	return &value
+}
+
+// formatPointer shows the value a pointer points to, or nil.
+func formatPointer(value interface{}) interface{} {
+	if v := reflect.ValueOf(value); !v.IsNil() {
+		return v.Elem().Interface()
+	}
+	return nil
+}
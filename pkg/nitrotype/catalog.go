@@ -0,0 +1,188 @@
+package nitrotype
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NTPlayerCatalog is a racer profile with the cars and loot it owns looked up in NTGLOBALS.
+type NTPlayerCatalog struct {
+	NTPlayer
+	OwnedCars []CatalogCar  `json:"ownedCars"`
+	OwnedLoot []CatalogLoot `json:"ownedLoot"`
+	Unknown   CatalogIDs    `json:"unknown"`
+}
+
+// CatalogCar is an owned car with its catalogue entry, Car is nil when the catalogue does not list it.
+type CatalogCar struct {
+	CarID        int    `json:"carID"`
+	Status       string `json:"status"`
+	CarHueAngle  int    `json:"carHueAngle"`
+	CreatedStamp int    `json:"createdStamp"`
+	Current      bool   `json:"current"`
+	Car          *Car   `json:"car"`
+	Rarity       string `json:"rarity,omitempty"`
+	ImageURL     string `json:"imageURL,omitempty"`
+}
+
+// CatalogLoot is an owned loot item with its catalogue entry, Loot is nil when the catalogue does not list it.
+type CatalogLoot struct {
+	NTPlayerLoot
+	Loot   *Loot  `json:"catalog"`
+	Rarity string `json:"rarity,omitempty"`
+}
+
+// CatalogIDs lists car and loot IDs a racer has that the catalogue does not.
+type CatalogIDs struct {
+	Cars []int `json:"cars"`
+	Loot []int `json:"loot"`
+}
+
+// JoinCatalog looks up the cars, garage and loot of a racer in the NTGLOBALS catalogue.
+func JoinCatalog(player *NTPlayer, globals *NTGlobals) *NTPlayerCatalog {
+	cars := make(map[int]*Car, len(globals.Cars))
+	for i := range globals.Cars {
+		cars[globals.Cars[i].CarID] = &globals.Cars[i]
+	}
+	loot := make(map[int]*Loot, len(globals.Loot))
+	for i := range globals.Loot {
+		loot[globals.Loot[i].LootID] = &globals.Loot[i]
+	}
+
+	output := &NTPlayerCatalog{
+		NTPlayer:  *player,
+		OwnedCars: make([]CatalogCar, 0, len(player.Cars)),
+		OwnedLoot: make([]CatalogLoot, 0, len(player.Loot)),
+		Unknown: CatalogIDs{
+			Cars: []int{},
+			Loot: []int{},
+		},
+	}
+	unknownCars := map[int]bool{}
+
+	for _, owned := range player.Cars {
+		item := CatalogCar{
+			CarID:        owned.CarID,
+			Status:       owned.Status,
+			CarHueAngle:  owned.CarHueAngle,
+			CreatedStamp: owned.CreatedStamp,
+			Current:      owned.CarID == player.CarID,
+		}
+		if car, ok := cars[owned.CarID]; ok {
+			item.Car = car
+			item.Rarity = car.Options.Rarity
+			item.ImageURL = globals.CarImageURL(*car, owned.CarHueAngle)
+		} else {
+			unknownCars[owned.CarID] = true
+		}
+		output.OwnedCars = append(output.OwnedCars, item)
+	}
+
+	// The garage lists car IDs by slot, empty slots are null
+	for _, slot := range player.Garage {
+		value, ok := slot.Value.(string)
+		if !ok || value == "" {
+			continue
+		}
+		carID, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		if _, ok := cars[carID]; !ok {
+			unknownCars[carID] = true
+		}
+	}
+
+	unknownLoot := map[int]bool{}
+	for _, owned := range player.Loot {
+		item := CatalogLoot{
+			NTPlayerLoot: owned,
+			Rarity:       owned.Options.Rarity,
+		}
+		if entry, ok := loot[owned.LootID]; ok {
+			item.Loot = entry
+			item.Rarity = entry.Options.Rarity
+		} else {
+			unknownLoot[owned.LootID] = true
+		}
+		output.OwnedLoot = append(output.OwnedLoot, item)
+	}
+
+	for carID := range unknownCars {
+		output.Unknown.Cars = append(output.Unknown.Cars, carID)
+	}
+	sort.Ints(output.Unknown.Cars)
+	for lootID := range unknownLoot {
+		output.Unknown.Loot = append(output.Unknown.Loot, lootID)
+	}
+	sort.Ints(output.Unknown.Loot)
+
+	return output
+}
+
+// CarImageSize picks between the small and large car images.
+type CarImageSize string
+
+const (
+	CarImageSmall CarImageSize = "small"
+	CarImageLarge CarImageSize = "large"
+)
+
+// CarImageURL returns the full URL of the large image of a car, painted when hue is not zero.
+// An empty string is returned when the URL cannot be worked out.
+func (g *NTGlobals) CarImageURL(car Car, hue int) string {
+	imageURL, err := g.resolveCarImage(car, CarImageLarge, hue)
+	if err != nil {
+		return ""
+	}
+	return imageURL
+}
+
+// CarImageSizeURL fills CAR_URL, or CAR_PAINTED_URL when hue is not zero, with the image of a car.
+// Painted images are named after the unpainted image with the hue appended, such as 9_large_1_60.png.
+// Hues are angles so they wrap around at 360.
+func (g *NTGlobals) CarImageSizeURL(car Car, size CarImageSize, hue int) string {
+	src := car.Options.LargeSrc
+	if size == CarImageSmall {
+		src = car.Options.SmallSrc
+	}
+	hue = ((hue % 360) + 360) % 360
+	if hue == 0 {
+		return g.CarURL + src
+	}
+	name := strings.TrimSuffix(src, ".png")
+	return fmt.Sprintf("%s%s_%d.png", g.CarPaintedURL, name, hue)
+}
+
+// CarImage returns the full URL of a car image by car ID.
+// CAR_URL and CAR_PAINTED_URL may be paths, which are resolved against the nitrotype entry of SITES.
+func (g *NTGlobals) CarImage(carID int, size CarImageSize, hue int) (string, error) {
+	if size != CarImageSmall && size != CarImageLarge {
+		return "", fmt.Errorf("%w: unknown size %q", ErrInvalidCarImage, size)
+	}
+	for _, car := range g.Cars {
+		if car.CarID == carID {
+			return g.resolveCarImage(car, size, hue)
+		}
+	}
+	return "", fmt.Errorf("%w: %d", ErrCarNotFound, carID)
+}
+
+// resolveCarImage resolves the image of a car against the nitrotype entry of SITES when CAR_URL is a path.
+func (g *NTGlobals) resolveCarImage(car Car, size CarImageSize, hue int) (string, error) {
+	imageURL, err := url.Parse(g.CarImageSizeURL(car, size, hue))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidCarImage, err)
+	}
+	if imageURL.IsAbs() {
+		return imageURL.String(), nil
+	}
+	base, err := url.Parse(baseURLOrDefault(g.Sites["nitrotype"]))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidCarImage, err)
+	}
+	return base.ResolveReference(imageURL).String(), nil
+}
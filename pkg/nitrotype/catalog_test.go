@@ -0,0 +1,35 @@
+package nitrotype_test
+
+import (
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"nt-bootstrap-scraper/pkg/nitrotype/nitrotypetest"
+	"testing"
+)
+
+func TestJoinCatalogImageURL(t *testing.T) {
+	globals, err := nitrotypetest.DefaultGlobals().Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	racer := nitrotypetest.DefaultRacers()[0]
+
+	catalog := nitrotype.JoinCatalog(&racer, globals)
+	var joined string
+	for _, car := range catalog.OwnedCars {
+		if car.CarID == 9 {
+			joined = car.ImageURL
+		}
+	}
+
+	// The catalogue and the image endpoint resolve CAR_PAINTED_URL the same way
+	want, err := globals.CarImage(9, nitrotype.CarImageLarge, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want != "https://www.nitrotype.com/cars/painted/9_large_1_60.png" {
+		t.Errorf("CarImage() = %q", want)
+	}
+	if joined != want {
+		t.Errorf("ImageURL = %q, want %q", joined, want)
+	}
+}
@@ -0,0 +1,304 @@
+package nitrotype
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeType is what happened to an entity between two bootstrap files.
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// diffSection is a list of entities in NTGLOBALS and the fields identifying each entity.
+// Sections without ID fields are maps and are keyed by the map key.
+type diffSection struct {
+	Path     []string
+	IDFields []string
+}
+
+// diffSections are the sections compared by BootstrapDiff, in the order changes are reported.
+var diffSections = []diffSection{
+	{Path: []string{"CARS"}, IDFields: []string{"carID"}},
+	{Path: []string{"LOOT"}, IDFields: []string{"lootID"}},
+	// shopReleaseID is not unique across categories, so rotations are keyed by both
+	{Path: []string{"SHOP"}, IDFields: []string{"category", "shopReleaseID"}},
+	{Path: []string{"DEALERSHIP"}, IDFields: []string{"dealershipID"}},
+	{Path: []string{"ACHIEVEMENTS", "LIST"}, IDFields: []string{"achievementID"}},
+	{Path: []string{"PRODUCTS"}},
+	{Path: []string{"CHALLENGES"}, IDFields: []string{"challengeID"}},
+}
+
+// BootstrapDiff lists the entities that differ between two bootstrap files.
+type BootstrapDiff struct {
+	Summary map[string]DiffCounts `json:"summary"`
+	Changes []EntityChange        `json:"changes"`
+}
+
+// DiffCounts counts the changes in one section.
+type DiffCounts struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+// EntityChange is one entity added, removed or changed.
+// Added entities have After, removed entities have Before and changed entities list the fields that differ.
+type EntityChange struct {
+	Section string        `json:"section"`
+	ID      string        `json:"id"`
+	Name    string        `json:"name,omitempty"`
+	Type    ChangeType    `json:"type"`
+	Before  interface{}   `json:"before,omitempty"`
+	After   interface{}   `json:"after,omitempty"`
+	Fields  []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is a field of a changed entity, Path is dotted for nested objects.
+// Lists are compared whole, a missing field is null.
+type FieldChange struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// DiffBootstrap compares the entities of two bootstrap files by ID.
+func DiffBootstrap(from NTGlobalsLegacy, to NTGlobalsLegacy) (*BootstrapDiff, error) {
+	before, err := plainJSON(from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := plainJSON(to)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &BootstrapDiff{
+		Summary: map[string]DiffCounts{},
+		Changes: []EntityChange{},
+	}
+	for _, section := range diffSections {
+		name := strings.Join(section.Path, ".")
+		beforeEntities := section.entities(before)
+		afterEntities := section.entities(after)
+
+		ids := map[string]bool{}
+		for id := range beforeEntities {
+			ids[id] = true
+		}
+		for id := range afterEntities {
+			ids[id] = true
+		}
+		sorted := make([]string, 0, len(ids))
+		for id := range ids {
+			sorted = append(sorted, id)
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			return lessID(sorted[i], sorted[j])
+		})
+
+		counts := DiffCounts{}
+		for _, id := range sorted {
+			for _, pair := range entityPairs(id, beforeEntities[id], afterEntities[id]) {
+				change := EntityChange{Section: name, ID: pair.ID}
+				switch {
+				case !pair.InBefore:
+					change.Type, change.After, change.Name = ChangeAdded, pair.After, entityName(pair.After)
+					counts.Added++
+				case !pair.InAfter:
+					change.Type, change.Before, change.Name = ChangeRemoved, pair.Before, entityName(pair.Before)
+					counts.Removed++
+				default:
+					change.Fields = diffFields("", pair.Before, pair.After, nil)
+					if len(change.Fields) == 0 {
+						continue
+					}
+					change.Type, change.Name = ChangeChanged, entityName(pair.After)
+					counts.Changed++
+				}
+				output.Changes = append(output.Changes, change)
+			}
+		}
+		output.Summary[name] = counts
+	}
+	return output, nil
+}
+
+// HasChanges reports whether any entity differs.
+func (d *BootstrapDiff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+// entities returns the section of a bootstrap file grouped by ID, compound IDs are joined with a slash.
+// List entries missing an ID field are keyed by their position instead, entries repeating an ID
+// share a group in list order so neither copy is lost.
+func (s diffSection) entities(root interface{}) map[string][]interface{} {
+	value := root
+	for _, key := range s.Path {
+		object, _ := value.(map[string]interface{})
+		value = object[key]
+	}
+
+	output := map[string][]interface{}{}
+	switch items := value.(type) {
+	case map[string]interface{}:
+		for id, item := range items {
+			output[id] = []interface{}{item}
+		}
+	case []interface{}:
+		for i, item := range items {
+			id, ok := s.entityID(item)
+			if !ok {
+				id = fmt.Sprintf("[%d]", i)
+			}
+			output[id] = append(output[id], item)
+		}
+	}
+	return output
+}
+
+// entityPair is an entity as it was before and after, either side is missing for added and removed entities.
+type entityPair struct {
+	ID       string
+	Before   interface{}
+	After    interface{}
+	InBefore bool
+	InAfter  bool
+}
+
+// entityPairs matches up the entries of both files sharing an ID.
+// When more than one entry has the ID, identical entries are matched first wherever they are in the list,
+// so duplicates moving around are not reported, and the rest are paired in list order.
+// Those duplicates are told apart by their place among the entries with the ID, as in "1[2]".
+func entityPairs(id string, before []interface{}, after []interface{}) []entityPair {
+	if len(before) <= 1 && len(after) <= 1 {
+		pair := entityPair{ID: id}
+		if len(before) == 1 {
+			pair.Before, pair.InBefore = before[0], true
+		}
+		if len(after) == 1 {
+			pair.After, pair.InAfter = after[0], true
+		}
+		return []entityPair{pair}
+	}
+
+	matched := make([]bool, len(after))
+	var unmatchedBefore, unmatchedAfter []int
+	for i, beforeEntity := range before {
+		found := false
+		for j, afterEntity := range after {
+			if !matched[j] && reflect.DeepEqual(beforeEntity, afterEntity) {
+				matched[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			unmatchedBefore = append(unmatchedBefore, i)
+		}
+	}
+	for j := range after {
+		if !matched[j] {
+			unmatchedAfter = append(unmatchedAfter, j)
+		}
+	}
+
+	output := []entityPair{}
+	for k := 0; k < len(unmatchedBefore) || k < len(unmatchedAfter); k++ {
+		var pair entityPair
+		if k < len(unmatchedBefore) {
+			i := unmatchedBefore[k]
+			pair.ID, pair.Before, pair.InBefore = fmt.Sprintf("%s[%d]", id, i), before[i], true
+		}
+		if k < len(unmatchedAfter) {
+			j := unmatchedAfter[k]
+			pair.After, pair.InAfter = after[j], true
+			if !pair.InBefore {
+				pair.ID = fmt.Sprintf("%s[%d]", id, j)
+			}
+		}
+		output = append(output, pair)
+	}
+	return output
+}
+
+// entityID joins the ID fields of a list entry, ok is false when any of them is missing.
+func (s diffSection) entityID(item interface{}) (string, bool) {
+	object, ok := item.(map[string]interface{})
+	if !ok || len(s.IDFields) == 0 {
+		return "", false
+	}
+	parts := make([]string, 0, len(s.IDFields))
+	for _, field := range s.IDFields {
+		if object[field] == nil {
+			return "", false
+		}
+		parts = append(parts, fmt.Sprint(object[field]))
+	}
+	return strings.Join(parts, "/"), true
+}
+
+// diffFields lists the fields that differ between two values, descending into objects.
+func diffFields(path string, before interface{}, after interface{}, output []FieldChange) []FieldChange {
+	beforeObject, beforeIsObject := before.(map[string]interface{})
+	afterObject, afterIsObject := after.(map[string]interface{})
+	if !beforeIsObject || !afterIsObject {
+		if !reflect.DeepEqual(before, after) {
+			output = append(output, FieldChange{Path: path, Before: before, After: after})
+		}
+		return output
+	}
+
+	keys := map[string]bool{}
+	for key := range beforeObject {
+		keys[key] = true
+	}
+	for key := range afterObject {
+		keys[key] = true
+	}
+	for _, key := range sortedKeys(keys) {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		output = diffFields(fieldPath, beforeObject[key], afterObject[key], output)
+	}
+	return output
+}
+
+// entityName returns the name field of an entity, if it has one.
+func entityName(entity interface{}) string {
+	object, _ := entity.(map[string]interface{})
+	name, _ := object["name"].(string)
+	return name
+}
+
+// lessID orders numeric IDs by value and everything else by text.
+func lessID(a string, b string) bool {
+	aNumber, aErr := strconv.ParseFloat(a, 64)
+	bNumber, bErr := strconv.ParseFloat(b, 64)
+	if aErr == nil && bErr == nil && aNumber != bNumber {
+		return aNumber < bNumber
+	}
+	return a < b
+}
+
+// plainJSON converts NTGLOBALS into plain JSON values, the scraper stores some entries as Go types.
+func plainJSON(raw NTGlobalsLegacy) (interface{}, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode NTGLOBALS: %w", err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("unable to decode NTGLOBALS: %w", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,97 @@
+package nitrotype_test
+
+import (
+	"encoding/json"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"testing"
+)
+
+func decodeLegacy(t *testing.T, data string) nitrotype.NTGlobalsLegacy {
+	t.Helper()
+	var output nitrotype.NTGlobalsLegacy
+	if err := json.Unmarshal([]byte(data), &output); err != nil {
+		t.Fatal(err)
+	}
+	return output
+}
+
+func TestDiffBootstrapSharedIDs(t *testing.T) {
+	from := decodeLegacy(t, `{
+		"SHOP": [
+			{"category": "daily", "shopReleaseID": 501, "items": [{"id": 9, "price": 200000}]},
+			{"category": "featured", "shopReleaseID": 501, "items": [{"id": 100, "price": 50000}]}
+		],
+		"CARS": [
+			{"carID": 1, "name": "Lambo"},
+			{"carID": 1, "name": "Lambo Copy"}
+		]
+	}`)
+	to := decodeLegacy(t, `{
+		"SHOP": [
+			{"category": "daily", "shopReleaseID": 501, "items": [{"id": 9, "price": 200000}]},
+			{"category": "featured", "shopReleaseID": 501, "items": [{"id": 100, "price": 40000}]}
+		],
+		"CARS": [
+			{"carID": 1, "name": "Lambo"}
+		]
+	}`)
+
+	diff, err := nitrotype.DiffBootstrap(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := map[string]nitrotype.EntityChange{}
+	for _, change := range diff.Changes {
+		changes[change.Section+" "+change.ID] = change
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Changes = %+v, want 2", diff.Changes)
+	}
+	// The featured rotation is changed rather than hidden behind the daily one with the same release
+	if change, ok := changes["SHOP featured/501"]; !ok || change.Type != nitrotype.ChangeChanged {
+		t.Errorf("SHOP featured/501 = %+v", change)
+	}
+	// The second car with the same ID is kept apart and reported as removed
+	if change, ok := changes["CARS 1[1]"]; !ok || change.Type != nitrotype.ChangeRemoved || change.Name != "Lambo Copy" {
+		t.Errorf("CARS 1[1] = %+v", change)
+	}
+}
+
+func TestDiffBootstrapReorderedDuplicates(t *testing.T) {
+	from := decodeLegacy(t, `{
+		"CARS": [
+			{"carID": 1, "name": "Lambo"},
+			{"carID": 2, "name": "Jeep"},
+			{"carID": 1, "name": "Lambo Copy"},
+			{"carID": 3, "name": "Van", "price": 100},
+			{"carID": 3, "name": "Van", "price": 200}
+		]
+	}`)
+	to := decodeLegacy(t, `{
+		"CARS": [
+			{"carID": 1, "name": "Lambo Copy"},
+			{"carID": 1, "name": "Lambo"},
+			{"carID": 2, "name": "Jeep"},
+			{"carID": 3, "name": "Van", "price": 250},
+			{"carID": 3, "name": "Van", "price": 100}
+		]
+	}`)
+
+	diff, err := nitrotype.DiffBootstrap(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Swapping the two cars sharing ID 1 is not a change, only the repriced van is
+	if len(diff.Changes) != 1 {
+		t.Fatalf("Changes = %+v, want 1", diff.Changes)
+	}
+	change := diff.Changes[0]
+	if change.ID != "3[1]" || change.Type != nitrotype.ChangeChanged || len(change.Fields) != 1 ||
+		change.Fields[0].Path != "price" || change.Fields[0].Before != 200.0 || change.Fields[0].After != 250.0 {
+		t.Errorf("change = %+v", change)
+	}
+	if counts := diff.Summary["CARS"]; counts != (nitrotype.DiffCounts{Changed: 1}) {
+		t.Errorf("Summary = %+v", counts)
+	}
+}
@@ -0,0 +1,119 @@
+package nitrotype
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrPlayerNotFound          = fmt.Errorf("player not found")
+	ErrTeamNotFound            = fmt.Errorf("team not found")
+	ErrBootstrapScriptNotFound = fmt.Errorf("bootstrap.js not found")
+	ErrNTGlobalsMissing        = fmt.Errorf("NTGLOBALS not found")
+	ErrGlobalsDecode           = fmt.Errorf("NTGLOBALS sections do not match the typed model")
+	ErrTopPlayersParse         = fmt.Errorf("unable to parse top players")
+	ErrScoreboardParse         = fmt.Errorf("unable to parse scoreboard")
+	ErrInvalidScoreboard       = fmt.Errorf("unknown scoreboard")
+	ErrLeaderboardNotReady     = fmt.Errorf("leaderboard profiles have not been resolved yet")
+	ErrSeasonNotFound          = fmt.Errorf("season not found")
+	ErrCarNotFound             = fmt.Errorf("car not found")
+	ErrInvalidCarImage         = fmt.Errorf("invalid car image")
+	ErrTimeout                 = fmt.Errorf("timed out waiting for nitro type")
+	ErrNetwork                 = fmt.Errorf("unable to reach nitro type")
+	ErrBotChallenge            = fmt.Errorf("blocked by a bot challenge page")
+	ErrMaintenance             = fmt.Errorf("nitro type is down for maintenance")
+	ErrUpstreamError           = fmt.Errorf("nitro type served an error page")
+	ErrBrowserLaunch           = fmt.Errorf("unable to launch browser")
+	ErrBrowserClosed           = fmt.Errorf("browser has been closed")
+)
+
+// ScrapeError tags an underlying failure with one of the sentinel errors above,
+// so callers can use errors.Is on the kind while keeping the original cause.
+type ScrapeError struct {
+	Kind error
+	Err  error
+}
+
+func (e *ScrapeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Kind, e.Err)
+}
+
+func (e *ScrapeError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *ScrapeError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatusError is returned when Nitro Type responds with an unexpected status code.
+type HTTPStatusError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s", e.StatusCode, e.URL)
+}
+
+// IsTransient reports whether err is likely to go away if the request is made again later.
+func IsTransient(err error) bool {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == 429 || statusErr.StatusCode >= 500
+	}
+	return errors.Is(err, ErrTimeout) || errors.Is(err, ErrNetwork) || errors.Is(err, ErrBotChallenge) ||
+		errors.Is(err, ErrMaintenance) || errors.Is(err, ErrUpstreamError) || errors.Is(err, ErrBrowserLaunch) ||
+		isNetworkError(err)
+}
+
+// classifyError converts context deadlines into ErrTimeout and connection failures into ErrNetwork.
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, ErrTimeout) || errors.Is(err, ErrNetwork) {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &ScrapeError{Kind: ErrTimeout, Err: err}
+	}
+	if isNetworkError(err) {
+		return &ScrapeError{Kind: ErrNetwork, Err: err}
+	}
+	return err
+}
+
+// isNetworkError reports whether err is a failure to reach Nitro Type or a connection dropped mid response.
+// Chrome reports these as net::ERR_* page load errors, which chromedp only gives as text.
+func isNetworkError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || strings.Contains(err.Error(), "net::ERR_") {
+		return true
+	}
+
+	// url.Error implements net.Error for any failed request, so only look at what it wraps
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if urlErr.Err == io.EOF {
+			return true
+		}
+		err = urlErr.Err
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isUndefinedValue reports whether chromedp failed because a script returned undefined.
+// chromedp does not export this error so the message is compared instead.
+func isUndefinedValue(err error) bool {
+	return err != nil && err.Error() == "encountered an undefined value"
+}
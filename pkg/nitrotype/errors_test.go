@@ -0,0 +1,121 @@
+package nitrotype_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "connection refused",
+			err:  &url.Error{Op: "Get", URL: "https://www.nitrotype.com/", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}},
+			want: true,
+		},
+		{
+			name: "connection reset",
+			err:  &url.Error{Op: "Get", URL: "https://www.nitrotype.com/", Err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}},
+			want: true,
+		},
+		{
+			name: "temporary dns failure",
+			err:  &url.Error{Op: "Get", URL: "https://www.nitrotype.com/", Err: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "server misbehaving", Name: "www.nitrotype.com", IsTemporary: true}}},
+			want: true,
+		},
+		{
+			name: "unknown host",
+			err:  &url.Error{Op: "Get", URL: "https://nitrotype.invalid/", Err: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "nitrotype.invalid", IsNotFound: true}}},
+			want: false,
+		},
+		{
+			name: "connection closed before response",
+			err:  &url.Error{Op: "Get", URL: "https://www.nitrotype.com/", Err: io.EOF},
+			want: true,
+		},
+		{
+			name: "truncated body",
+			err:  fmt.Errorf("unable to read response: %w", io.ErrUnexpectedEOF),
+			want: true,
+		},
+		{
+			name: "chrome connection reset",
+			err:  errors.New("page load error net::ERR_CONNECTION_RESET"),
+			want: true,
+		},
+		{
+			name: "bad request url",
+			err:  &url.Error{Op: "Get", URL: "ftp://www.nitrotype.com/", Err: errors.New("unsupported protocol scheme \"ftp\"")},
+			want: false,
+		},
+		{
+			name: "canceled",
+			err:  &url.Error{Op: "Get", URL: "https://www.nitrotype.com/", Err: context.Canceled},
+			want: false,
+		},
+		{
+			name: "timeout",
+			err:  nitrotype.ErrTimeout,
+			want: true,
+		},
+		{
+			name: "rate limited",
+			err:  &nitrotype.HTTPStatusError{StatusCode: http.StatusTooManyRequests},
+			want: true,
+		},
+		{
+			name: "not found status",
+			err:  &nitrotype.HTTPStatusError{StatusCode: http.StatusNotFound},
+			want: false,
+		},
+		{
+			name: "player not found",
+			err:  nitrotype.ErrPlayerNotFound,
+			want: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := nitrotype.IsTransient(test.err); got != test.want {
+				t.Errorf("IsTransient(%v) = %v, want %v", test.err, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRetryFetcherRetriesConnectionFailures(t *testing.T) {
+	// A server that is closed straight away leaves an address refusing connections
+	server := httptest.NewServer(http.NotFoundHandler())
+	baseURL := server.URL
+	server.Close()
+
+	attempts := 0
+	fetcher := nitrotype.NewRetryFetcher(nitrotype.HTTPFetcher{BaseURL: baseURL}, nitrotype.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		OnAttempt: func(attempt nitrotype.RetryAttempt) {
+			attempts = attempt.Attempt
+		},
+	})
+	_, err := fetcher.GetBootstrapData(context.Background())
+	if !errors.Is(err, nitrotype.ErrNetwork) {
+		t.Fatalf("GetBootstrapData() error = %v, want ErrNetwork", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
@@ -0,0 +1,358 @@
+package nitrotype
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/patrickmn/go-cache"
+)
+
+const (
+	BootstrapCacheKey        = "bootstrap_data"
+	GlobalsCacheKey          = "bootstrap_globals"
+	PlayerCacheKeyPrefix     = "player_data_"
+	TeamCacheKeyPrefix       = "team_data_"
+	ScoreboardCacheKeyPrefix = "scoreboard_data_"
+)
+
+// Fetcher collects data from Nitro Type.
+type Fetcher interface {
+	GetBootstrapData(ctx context.Context) (*NTGlobalsLegacy, error)
+	GetPlayerData(ctx context.Context, username string) (*NTPlayer, error)
+	GetTeamData(ctx context.Context, tag string) (*NTTeam, error)
+	GetScoreboard(ctx context.Context, kind ScoreboardKind, timeframe ScoreboardTimeframe) (*NTScoreboard, error)
+}
+
+// ScoreboardCacheKey is the cache key a scoreboard is stored under.
+func ScoreboardCacheKey(kind ScoreboardKind, timeframe ScoreboardTimeframe) string {
+	return ScoreboardCacheKeyPrefix + string(kind) + "_" + string(timeframe)
+}
+
+// GlobalsFetcher is a Fetcher that can also return NTGLOBALS already decoded.
+type GlobalsFetcher interface {
+	Fetcher
+	GetGlobals(ctx context.Context) (*NTGlobals, error)
+}
+
+// GetGlobals retrieves the NTGLOBALS data from the Fetcher as a typed NTGlobals.
+// Sections that could not be decoded are left empty and reported with a *GlobalsDecodeError
+// returned alongside the globals, so callers only needing other sections can carry on.
+// The returned globals may be shared and must not be modified.
+func GetGlobals(ctx context.Context, f Fetcher) (*NTGlobals, error) {
+	if g, ok := f.(GlobalsFetcher); ok {
+		return g.GetGlobals(ctx)
+	}
+	source, err := f.GetBootstrapData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return source.Decode()
+}
+
+// GetRequiredGlobals is GetGlobals for callers that only rely on some sections.
+// It fails if one of the needed sections could not be decoded, failures in the other sections are passed
+// to warn, when it is not nil, and those sections are left empty.
+func GetRequiredGlobals(ctx context.Context, f Fetcher, warn func(err error), needs ...string) (*NTGlobals, error) {
+	globals, err := GetGlobals(ctx, f)
+	var decodeErr *GlobalsDecodeError
+	if !errors.As(err, &decodeErr) {
+		return globals, err
+	}
+	for _, section := range needs {
+		if decodeErr.Sections[section] != nil {
+			return nil, err
+		}
+	}
+	if warn != nil {
+		warn(err)
+	}
+	return globals, nil
+}
+
+// ChromeFetcher collects data by driving a headless Chrome browser.
+type ChromeFetcher struct {
+	// BaseURL is the site origin, defaults to DefaultBaseURL.
+	BaseURL string
+
+	// Browser provides tabs from a shared Chrome, a new Chrome is started per request when nil.
+	Browser *Browser
+}
+
+func (f ChromeFetcher) GetBootstrapData(ctx context.Context) (*NTGlobalsLegacy, error) {
+	ctx, cancel, err := f.newTab(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	source, err := getBootstrapDataChrome(ctx, baseURLOrDefault(f.BaseURL))
+	return source, classifyError(err)
+}
+
+func (f ChromeFetcher) GetPlayerData(ctx context.Context, username string) (*NTPlayer, error) {
+	ctx, cancel, err := f.newTab(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	racer, err := getPlayerDataChrome(ctx, baseURLOrDefault(f.BaseURL), username)
+	return racer, classifyError(err)
+}
+
+func (f ChromeFetcher) GetTeamData(ctx context.Context, tag string) (*NTTeam, error) {
+	ctx, cancel, err := f.newTab(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	team, err := getTeamDataChrome(ctx, baseURLOrDefault(f.BaseURL), tag)
+	return team, classifyError(err)
+}
+
+func (f ChromeFetcher) GetScoreboard(ctx context.Context, kind ScoreboardKind, timeframe ScoreboardTimeframe) (*NTScoreboard, error) {
+	if err := ValidateScoreboard(kind, timeframe); err != nil {
+		return nil, err
+	}
+	ctx, cancel, err := f.newTab(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	scoreboard, err := getScoreboardChrome(ctx, baseURLOrDefault(f.BaseURL), kind, timeframe)
+	return scoreboard, classifyError(err)
+}
+
+// newTab opens a tab on the shared browser if there is one.
+func (f ChromeFetcher) newTab(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if f.Browser != nil {
+		return f.Browser.NewTab(ctx)
+	}
+	return newStandaloneTab(ctx)
+}
+
+// HTTPFetcher collects data with plain HTTP requests.
+type HTTPFetcher struct {
+	// Client is used for all requests, defaults to a client that honours WithProxy.
+	Client *http.Client
+
+	// BaseURL is the site origin, defaults to DefaultBaseURL.
+	BaseURL string
+}
+
+func (f HTTPFetcher) GetBootstrapData(ctx context.Context) (*NTGlobalsLegacy, error) {
+	source, err := getBootstrapDataHTTP(ctx, f.Client, baseURLOrDefault(f.BaseURL))
+	return source, classifyError(err)
+}
+
+func (f HTTPFetcher) GetPlayerData(ctx context.Context, username string) (*NTPlayer, error) {
+	racer, err := getPlayerDataHTTP(ctx, f.Client, baseURLOrDefault(f.BaseURL), username)
+	return racer, classifyError(err)
+}
+
+func (f HTTPFetcher) GetTeamData(ctx context.Context, tag string) (*NTTeam, error) {
+	team, err := getTeamDataHTTP(ctx, f.Client, baseURLOrDefault(f.BaseURL), tag)
+	return team, classifyError(err)
+}
+
+func (f HTTPFetcher) GetScoreboard(ctx context.Context, kind ScoreboardKind, timeframe ScoreboardTimeframe) (*NTScoreboard, error) {
+	scoreboard, err := getScoreboardHTTP(ctx, f.Client, baseURLOrDefault(f.BaseURL), kind, timeframe)
+	return scoreboard, classifyError(err)
+}
+
+// baseURLOrDefault returns the site origin without a trailing slash.
+func baseURLOrDefault(baseURL string) string {
+	if baseURL == "" {
+		return DefaultBaseURL
+	}
+	return strings.TrimRight(baseURL, "/")
+}
+
+// FixtureFetcher replays previously saved JSON output from a directory.
+// The directory contains bootstrap.json, racer/{username}.json, team/{tag}.json
+// and scoreboard/{kind}_{timeframe}.json files.
+type FixtureFetcher struct {
+	Dir string
+}
+
+func (f FixtureFetcher) GetBootstrapData(ctx context.Context) (*NTGlobalsLegacy, error) {
+	var output NTGlobalsLegacy
+	if err := readFixture(filepath.Join(f.Dir, "bootstrap.json"), &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+func (f FixtureFetcher) GetPlayerData(ctx context.Context, username string) (*NTPlayer, error) {
+	if !isFixtureName(username) {
+		return nil, ErrPlayerNotFound
+	}
+	var output NTPlayer
+	err := readFixture(filepath.Join(f.Dir, "racer", username+".json"), &output)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrPlayerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+func (f FixtureFetcher) GetTeamData(ctx context.Context, tag string) (*NTTeam, error) {
+	if !isFixtureName(tag) {
+		return nil, ErrTeamNotFound
+	}
+	var output NTTeam
+	err := readFixture(filepath.Join(f.Dir, "team", tag+".json"), &output)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	output.setOfficers()
+	return &output, nil
+}
+
+func (f FixtureFetcher) GetScoreboard(ctx context.Context, kind ScoreboardKind, timeframe ScoreboardTimeframe) (*NTScoreboard, error) {
+	if err := ValidateScoreboard(kind, timeframe); err != nil {
+		return nil, err
+	}
+	var output NTScoreboard
+	if err := readFixture(filepath.Join(f.Dir, "scoreboard", string(kind)+"_"+string(timeframe)+".json"), &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// isFixtureName reports whether name can be used as a fixture file name without leaving the directory.
+func isFixtureName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, `/\`) && name != "." && name != ".."
+}
+
+// readFixture decodes a JSON fixture file.
+func readFixture(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unable to parse fixture %s: %w", path, err)
+	}
+	return nil
+}
+
+// CachingFetcher serves data from the cache before asking the underlying Fetcher.
+type CachingFetcher struct {
+	next         Fetcher
+	cacheManager *cache.Cache
+}
+
+// NewCachingFetcher wraps a Fetcher with the given cache.
+func NewCachingFetcher(next Fetcher, cacheManager *cache.Cache) *CachingFetcher {
+	return &CachingFetcher{
+		next:         next,
+		cacheManager: cacheManager,
+	}
+}
+
+func (f *CachingFetcher) GetBootstrapData(ctx context.Context) (*NTGlobalsLegacy, error) {
+	cacheSource, found := f.cacheManager.Get(BootstrapCacheKey)
+	if found {
+		if source, ok := cacheSource.(*NTGlobalsLegacy); ok && source != nil {
+			return source, nil
+		}
+	}
+
+	source, err := f.next.GetBootstrapData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f.cacheManager.Set(BootstrapCacheKey, source, cache.DefaultExpiration)
+	return source, nil
+}
+
+// cachedGlobals is the decoded form of the bootstrap data cached at the same time.
+type cachedGlobals struct {
+	source  *NTGlobalsLegacy
+	globals *NTGlobals
+	err     error
+}
+
+// GetGlobals decodes the cached bootstrap data once and reuses the result until the data is replaced.
+func (f *CachingFetcher) GetGlobals(ctx context.Context) (*NTGlobals, error) {
+	source, err := f.GetBootstrapData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheSource, found := f.cacheManager.Get(GlobalsCacheKey)
+	if found {
+		if cached, ok := cacheSource.(*cachedGlobals); ok && cached.source == source {
+			return cached.globals, cached.err
+		}
+	}
+
+	globals, err := source.Decode()
+	f.cacheManager.Set(GlobalsCacheKey, &cachedGlobals{source: source, globals: globals, err: err}, cache.DefaultExpiration)
+	return globals, err
+}
+
+func (f *CachingFetcher) GetPlayerData(ctx context.Context, username string) (*NTPlayer, error) {
+	cacheName := PlayerCacheKeyPrefix + username
+	cacheSource, found := f.cacheManager.Get(cacheName)
+	if found {
+		if source, ok := cacheSource.(NTPlayer); ok {
+			return &source, nil
+		}
+	}
+
+	racer, err := f.next.GetPlayerData(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	f.cacheManager.Set(cacheName, *racer, cache.DefaultExpiration)
+	return racer, nil
+}
+
+func (f *CachingFetcher) GetTeamData(ctx context.Context, tag string) (*NTTeam, error) {
+	cacheName := TeamCacheKeyPrefix + strings.ToUpper(tag)
+	cacheSource, found := f.cacheManager.Get(cacheName)
+	if found {
+		if source, ok := cacheSource.(NTTeam); ok {
+			return &source, nil
+		}
+	}
+
+	team, err := f.next.GetTeamData(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	f.cacheManager.Set(cacheName, *team, cache.DefaultExpiration)
+	return team, nil
+}
+
+func (f *CachingFetcher) GetScoreboard(ctx context.Context, kind ScoreboardKind, timeframe ScoreboardTimeframe) (*NTScoreboard, error) {
+	cacheName := ScoreboardCacheKey(kind, timeframe)
+	cacheSource, found := f.cacheManager.Get(cacheName)
+	if found {
+		if source, ok := cacheSource.(*NTScoreboard); ok && source != nil {
+			return source, nil
+		}
+	}
+
+	scoreboard, err := f.next.GetScoreboard(ctx, kind, timeframe)
+	if err != nil {
+		return nil, err
+	}
+
+	f.cacheManager.Set(cacheName, scoreboard, cache.DefaultExpiration)
+	return scoreboard, nil
+}
@@ -0,0 +1,151 @@
+package nitrotype
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+var (
+	BootstrapScriptRegExp = regexp.MustCompile(`<script[^>]+src="([^"]*bootstrap\.js)"`)
+)
+
+// getBootstrapDataHTTP retrieves the NTGLOBALS variable from the given site without starting a browser.
+// The homepage is only used to locate bootstrap.js, which is then downloaded and parsed directly.
+func getBootstrapDataHTTP(ctx context.Context, client *http.Client, baseURL string) (*NTGlobalsLegacy, error) {
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
+	defer cancel()
+
+	// Find bootstrap.js
+	homepage, err := httpGet(ctx, client, baseURL+"/")
+	if err != nil {
+		return nil, err
+	}
+	matches := BootstrapScriptRegExp.FindSubmatch(homepage)
+	if len(matches) != 2 {
+		return nil, ErrBootstrapScriptNotFound
+	}
+	bootstrapSrc, err := resolveURL(baseURL, html.UnescapeString(string(matches[1])))
+	if err != nil {
+		return nil, err
+	}
+
+	downloadBytes, err := httpGet(ctx, client, bootstrapSrc)
+	if err != nil {
+		return nil, err
+	}
+
+	script, err := ParseBootstrapScript(downloadBytes)
+	if err != nil {
+		return nil, err
+	}
+	ntGlobals := script.Globals()
+	if err := setTopPlayers(ntGlobals, script); err != nil {
+		return nil, err
+	}
+
+	return &ntGlobals, nil
+}
+
+// getPlayerDataHTTP fetches the RACER_INFO data from racer profile page without starting a browser.
+func getPlayerDataHTTP(ctx context.Context, client *http.Client, baseURL string, username string) (*NTPlayer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
+	defer cancel()
+
+	downloadBytes, err := httpGet(ctx, client, baseURL+"/racer/"+url.PathEscape(username))
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return nil, ErrPlayerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePlayerPage(downloadBytes)
+}
+
+// getTeamDataHTTP fetches the TEAM_INFO data from the team page without starting a browser.
+func getTeamDataHTTP(ctx context.Context, client *http.Client, baseURL string, tag string) (*NTTeam, error) {
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
+	defer cancel()
+
+	downloadBytes, err := httpGet(ctx, client, baseURL+"/team/"+url.PathEscape(tag))
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return nil, ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTeamPage(downloadBytes)
+}
+
+// getScoreboardHTTP fetches the SCOREBOARD_INFO data from the scoreboard page without starting a browser.
+func getScoreboardHTTP(ctx context.Context, client *http.Client, baseURL string, kind ScoreboardKind, timeframe ScoreboardTimeframe) (*NTScoreboard, error) {
+	if err := ValidateScoreboard(kind, timeframe); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
+	defer cancel()
+
+	downloadBytes, err := httpGet(ctx, client, scoreboardURL(baseURL, kind, timeframe))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseScoreboardPage(downloadBytes, kind, timeframe)
+}
+
+// resolveURL resolves a script or page reference found on the site against its origin.
+func resolveURL(baseURL string, ref string) (string, error) {
+	base, err := url.Parse(baseURL + "/")
+	if err != nil {
+		return "", fmt.Errorf("invalid base url %q: %w", baseURL, err)
+	}
+	target, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %q: %w", ref, err)
+	}
+	return base.ResolveReference(target).String(), nil
+}
+
+// httpGet downloads the given url with the same identity as the browser.
+func httpGet(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	if client == nil {
+		client = proxyClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", "text/html,application/javascript,*/*")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPage(resp.StatusCode, resp.Header, body, url); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode, URL: url}
+	}
+
+	return body, nil
+}
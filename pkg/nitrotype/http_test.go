@@ -0,0 +1,115 @@
+package nitrotype_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"nt-bootstrap-scraper/pkg/nitrotype/nitrotypetest"
+	"testing"
+)
+
+func TestHTTPFetcherBootstrap(t *testing.T) {
+	server := nitrotypetest.NewServer()
+	defer server.Close()
+
+	source, err := server.Fetcher().GetBootstrapData(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var globals struct {
+		TopPlayers []nitrotype.RankItem `json:"TOP_PLAYERS"`
+		TopTeams   []nitrotype.RankItem `json:"TOP_TEAMS"`
+		Cars       []interface{}        `json:"CARS"`
+		Sites      map[string]string    `json:"SITES"`
+	}
+	if err := json.Unmarshal(data, &globals); err != nil {
+		t.Fatal(err)
+	}
+	if len(globals.TopPlayers) != 2 || globals.TopPlayers[0].ID != 12 || len(globals.TopTeams) != 1 {
+		t.Errorf("TopPlayers = %+v, TopTeams = %+v", globals.TopPlayers, globals.TopTeams)
+	}
+	if len(globals.Cars) == 0 || globals.Sites["nitrotype"] != "https://www.nitrotype.com" {
+		t.Errorf("Cars = %d, Sites = %v", len(globals.Cars), globals.Sites)
+	}
+}
+
+func TestHTTPFetcherPlayer(t *testing.T) {
+	server := nitrotypetest.NewServer()
+	defer server.Close()
+	fetcher := server.Fetcher()
+
+	racer, err := fetcher.GetPlayerData(context.Background(), "Speedy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if racer.UserID != 12 || racer.Username != "speedy" || racer.Level != 120 || len(racer.Cars) != 3 {
+		t.Errorf("GetPlayerData() = %+v", racer)
+	}
+
+	if _, err := fetcher.GetPlayerData(context.Background(), "nobody"); !errors.Is(err, nitrotype.ErrPlayerNotFound) {
+		t.Errorf("GetPlayerData() missing racer error = %v, want ErrPlayerNotFound", err)
+	}
+}
+
+func TestHTTPFetcherUnavailable(t *testing.T) {
+	tests := []struct {
+		kind nitrotype.PageKind
+		want error
+	}{
+		{kind: nitrotype.PageMaintenance, want: nitrotype.ErrMaintenance},
+		{kind: nitrotype.PageChallenge, want: nitrotype.ErrBotChallenge},
+		{kind: nitrotype.PageError, want: nitrotype.ErrUpstreamError},
+	}
+	for _, test := range tests {
+		t.Run(string(test.kind), func(t *testing.T) {
+			server := nitrotypetest.NewServer()
+			defer server.Close()
+			server.SetUnavailable(test.kind)
+			fetcher := server.Fetcher()
+
+			if _, err := fetcher.GetBootstrapData(context.Background()); !errors.Is(err, test.want) {
+				t.Errorf("GetBootstrapData() error = %v, want %v", err, test.want)
+			}
+			// An outage page is not mistaken for a missing racer
+			if _, err := fetcher.GetPlayerData(context.Background(), "speedy"); !errors.Is(err, test.want) {
+				t.Errorf("GetPlayerData() error = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestGetRequiredGlobals(t *testing.T) {
+	server := nitrotypetest.NewServer()
+	defer server.Close()
+	globals := nitrotypetest.DefaultGlobals()
+	globals["CASH_SENDING"] = "not an object"
+	server.SetGlobals(globals)
+
+	var warnings []error
+	warn := func(err error) { warnings = append(warnings, err) }
+
+	typed, err := nitrotype.GetRequiredGlobals(context.Background(), server.Fetcher(), warn, "CARS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(typed.Cars) == 0 {
+		t.Error("Cars is empty")
+	}
+	var decodeErr *nitrotype.GlobalsDecodeError
+	if len(warnings) != 1 || !errors.As(warnings[0], &decodeErr) || decodeErr.Sections["CASH_SENDING"] == nil {
+		t.Errorf("warnings = %v, want the CASH_SENDING decode error", warnings)
+	}
+
+	warnings = nil
+	if _, err := nitrotype.GetRequiredGlobals(context.Background(), server.Fetcher(), warn, "CARS", "CASH_SENDING"); !errors.As(err, &decodeErr) {
+		t.Errorf("error = %v, want GlobalsDecodeError for a needed section", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none when the error is returned", warnings)
+	}
+}
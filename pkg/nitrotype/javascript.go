@@ -0,0 +1,710 @@
+package nitrotype
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// JSKind is the type of a literal decoded from JavaScript source.
+type JSKind int
+
+const (
+	JSUndefined JSKind = iota
+	JSNull
+	JSBool
+	JSNumber
+	JSString
+	JSArray
+	JSObject
+)
+
+func (k JSKind) String() string {
+	switch k {
+	case JSNull:
+		return "null"
+	case JSBool:
+		return "boolean"
+	case JSNumber:
+		return "number"
+	case JSString:
+		return "string"
+	case JSArray:
+		return "array"
+	case JSObject:
+		return "object"
+	}
+	return "undefined"
+}
+
+// JSValue is a literal value decoded from JavaScript source.
+// Object properties keep the order they were written in.
+type JSValue struct {
+	Kind   JSKind
+	Offset int
+	Bool   bool
+	Number float64
+	String string
+	Array  []*JSValue
+	Object []JSProperty
+}
+
+// JSProperty is a single key of a JavaScript object literal.
+type JSProperty struct {
+	Key   string
+	Value *JSValue
+}
+
+// Get returns the value of the given object key, or nil when missing.
+func (v *JSValue) Get(key string) *JSValue {
+	if v == nil || v.Kind != JSObject {
+		return nil
+	}
+	var output *JSValue
+	for _, prop := range v.Object {
+		if prop.Key == key {
+			output = prop.Value
+		}
+	}
+	return output
+}
+
+// Interface converts the value into the same shape encoding/json decodes into.
+// Numbers become float64, arrays []interface{} and objects map[string]interface{}.
+// Infinity and NaN become nil, like JSON.stringify, as encoding/json cannot encode them.
+func (v *JSValue) Interface() interface{} {
+	if v == nil {
+		return nil
+	}
+	switch v.Kind {
+	case JSBool:
+		return v.Bool
+	case JSNumber:
+		if math.IsInf(v.Number, 0) || math.IsNaN(v.Number) {
+			return nil
+		}
+		return v.Number
+	case JSString:
+		return v.String
+	case JSArray:
+		output := make([]interface{}, len(v.Array))
+		for i, item := range v.Array {
+			output[i] = item.Interface()
+		}
+		return output
+	case JSObject:
+		output := make(map[string]interface{}, len(v.Object))
+		for _, prop := range v.Object {
+			output[prop.Key] = prop.Value.Interface()
+		}
+		return output
+	}
+	return nil
+}
+
+// JSSyntaxError describes where JavaScript source could not be understood.
+type JSSyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *JSSyntaxError) Error() string {
+	return fmt.Sprintf("%s (offset: %d)", e.Msg, e.Offset)
+}
+
+type jsTokenKind int
+
+const (
+	jsTokenEOF jsTokenKind = iota
+	jsTokenPunct
+	jsTokenString
+	jsTokenNumber
+	jsTokenIdent
+	jsTokenTemplate
+	jsTokenRegExp
+)
+
+// jsToken is a lexical token. Text holds the decoded value of strings and the raw source otherwise.
+type jsToken struct {
+	Kind   jsTokenKind
+	Text   string
+	Offset int
+}
+
+// jsRegExpPrefixKeywords are keywords after which a slash starts a regular expression.
+var jsRegExpPrefixKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true, "new": true,
+	"delete": true, "void": true, "throw": true, "case": true, "do": true, "else": true, "yield": true, "await": true,
+}
+
+// jsLexer splits JavaScript source into tokens. It understands enough of the language
+// (comments, strings, templates and regular expressions) to never mistake their contents for code.
+type jsLexer struct {
+	src    string
+	pos    int
+	tokens []jsToken
+
+	// braces tracks open curly braces per template substitution level
+	braces []int
+}
+
+// tokenizeJS returns every token in the source.
+func tokenizeJS(src string) ([]jsToken, error) {
+	l := &jsLexer{src: src}
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		l.tokens = append(l.tokens, tok)
+		if tok.Kind == jsTokenEOF {
+			return l.tokens, nil
+		}
+	}
+}
+
+func (l *jsLexer) errorf(offset int, format string, args ...interface{}) error {
+	return &JSSyntaxError{Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *jsLexer) next() (jsToken, error) {
+	if err := l.skipSpace(); err != nil {
+		return jsToken{}, err
+	}
+	if l.pos >= len(l.src) {
+		return jsToken{Kind: jsTokenEOF, Offset: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case c == '"' || c == '\'':
+		text, err := l.readString(c)
+		if err != nil {
+			return jsToken{}, err
+		}
+		return jsToken{Kind: jsTokenString, Text: text, Offset: start}, nil
+	case c == '`':
+		l.pos++
+		return l.readTemplate(start)
+	case c >= '0' && c <= '9' || c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1]):
+		return jsToken{Kind: jsTokenNumber, Text: l.readNumber(), Offset: start}, nil
+	case c == '/' && l.regExpAllowed():
+		if err := l.readRegExp(); err != nil {
+			return jsToken{}, err
+		}
+		return jsToken{Kind: jsTokenRegExp, Text: l.src[start:l.pos], Offset: start}, nil
+	case c == '{':
+		if n := len(l.braces); n > 0 {
+			l.braces[n-1]++
+		}
+	case c == '}':
+		if n := len(l.braces); n > 0 {
+			if l.braces[n-1] == 0 {
+				// closes a ${...} substitution, continue the template
+				l.braces = l.braces[:n-1]
+				l.pos++
+				return l.readTemplate(start)
+			}
+			l.braces[n-1]--
+		}
+	}
+
+	if r, size := utf8.DecodeRuneInString(l.src[l.pos:]); isIdentStart(r) {
+		l.pos += size
+		for l.pos < len(l.src) {
+			r, size = utf8.DecodeRuneInString(l.src[l.pos:])
+			if !isIdentPart(r) {
+				break
+			}
+			l.pos += size
+		}
+		return jsToken{Kind: jsTokenIdent, Text: l.src[start:l.pos], Offset: start}, nil
+	}
+
+	// Punctuators are kept as single characters, the value parser never needs more
+	l.pos++
+	return jsToken{Kind: jsTokenPunct, Text: l.src[start:l.pos], Offset: start}, nil
+}
+
+// skipSpace moves past whitespace and comments.
+func (l *jsLexer) skipSpace() error {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			end := strings.IndexAny(l.src[l.pos:], "\n\r")
+			if end < 0 {
+				l.pos = len(l.src)
+			} else {
+				l.pos += end
+			}
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return l.errorf(l.pos, "unterminated comment")
+			}
+			l.pos += end + 4
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			if r != '\uFEFF' && r != '\u2028' && r != '\u2029' && !unicode.Is(unicode.Zs, r) {
+				return nil
+			}
+			l.pos += size
+		}
+	}
+	return nil
+}
+
+// regExpAllowed reports whether a slash at the current position starts a regular expression rather than a division.
+func (l *jsLexer) regExpAllowed() bool {
+	if len(l.tokens) == 0 {
+		return true
+	}
+	prev := l.tokens[len(l.tokens)-1]
+	switch prev.Kind {
+	case jsTokenTemplate:
+		return strings.HasSuffix(prev.Text, "${")
+	case jsTokenNumber, jsTokenString, jsTokenRegExp:
+		return false
+	case jsTokenIdent:
+		return jsRegExpPrefixKeywords[prev.Text]
+	case jsTokenPunct:
+		return prev.Text != ")" && prev.Text != "]" && prev.Text != "}"
+	}
+	return true
+}
+
+func (l *jsLexer) readString(quote byte) (string, error) {
+	start := l.pos
+	l.pos++
+
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.src) {
+			return "", l.errorf(start, "unterminated string")
+		}
+		c := l.src[l.pos]
+		switch {
+		case c == quote:
+			l.pos++
+			return b.String(), nil
+		case c == '\\':
+			if err := l.readEscape(&b); err != nil {
+				return "", err
+			}
+		case c == '\n' || c == '\r':
+			return "", l.errorf(start, "unterminated string")
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+}
+
+// readEscape decodes a backslash escape sequence into b.
+func (l *jsLexer) readEscape(b *strings.Builder) error {
+	start := l.pos
+	l.pos++
+	if l.pos >= len(l.src) {
+		return l.errorf(start, "unterminated escape sequence")
+	}
+	c := l.src[l.pos]
+	l.pos++
+	switch c {
+	case 'n':
+		b.WriteByte('\n')
+	case 't':
+		b.WriteByte('\t')
+	case 'r':
+		b.WriteByte('\r')
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'v':
+		b.WriteByte('\v')
+	case '0':
+		b.WriteByte(0)
+	case '\r':
+		// line continuation
+		if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+			l.pos++
+		}
+	case '\n':
+		// line continuation
+	case 'x':
+		r, err := l.readHex(2)
+		if err != nil {
+			return err
+		}
+		b.WriteRune(r)
+	case 'u':
+		r, err := l.readUnicodeEscape()
+		if err != nil {
+			return err
+		}
+		if utf16.IsSurrogate(r) && strings.HasPrefix(l.src[l.pos:], `\u`) {
+			save := l.pos
+			l.pos += 2
+			r2, err := l.readUnicodeEscape()
+			if err == nil {
+				if combined := utf16.DecodeRune(r, r2); combined != utf8.RuneError {
+					b.WriteRune(combined)
+					return nil
+				}
+			}
+			l.pos = save
+		}
+		b.WriteRune(r)
+	default:
+		b.WriteByte(c)
+	}
+	return nil
+}
+
+func (l *jsLexer) readUnicodeEscape() (rune, error) {
+	if l.pos < len(l.src) && l.src[l.pos] == '{' {
+		end := strings.IndexByte(l.src[l.pos:], '}')
+		if end < 0 {
+			return 0, l.errorf(l.pos, "invalid unicode escape")
+		}
+		value, err := strconv.ParseUint(l.src[l.pos+1:l.pos+end], 16, 32)
+		if err != nil {
+			return 0, l.errorf(l.pos, "invalid unicode escape")
+		}
+		l.pos += end + 1
+		return rune(value), nil
+	}
+	return l.readHex(4)
+}
+
+func (l *jsLexer) readHex(digits int) (rune, error) {
+	if l.pos+digits > len(l.src) {
+		return 0, l.errorf(l.pos, "invalid hex escape")
+	}
+	value, err := strconv.ParseUint(l.src[l.pos:l.pos+digits], 16, 32)
+	if err != nil {
+		return 0, l.errorf(l.pos, "invalid hex escape")
+	}
+	l.pos += digits
+	return rune(value), nil
+}
+
+// readTemplate reads template literal text up to its end or the next substitution.
+// The opening backtick or closing brace has already been consumed.
+func (l *jsLexer) readTemplate(start int) (jsToken, error) {
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos += 2
+		case '`':
+			l.pos++
+			return jsToken{Kind: jsTokenTemplate, Text: l.src[start:l.pos], Offset: start}, nil
+		case '$':
+			if l.pos+1 < len(l.src) && l.src[l.pos+1] == '{' {
+				l.pos += 2
+				l.braces = append(l.braces, 0)
+				return jsToken{Kind: jsTokenTemplate, Text: l.src[start:l.pos], Offset: start}, nil
+			}
+			l.pos++
+		default:
+			l.pos++
+		}
+	}
+	return jsToken{}, l.errorf(start, "unterminated template literal")
+}
+
+func (l *jsLexer) readNumber() string {
+	start := l.pos
+	if l.src[l.pos] == '0' && l.pos+1 < len(l.src) && strings.IndexByte("xXoObB", l.src[l.pos+1]) >= 0 {
+		l.pos += 2
+		for l.pos < len(l.src) && (isHexDigit(l.src[l.pos]) || l.src[l.pos] == '_') {
+			l.pos++
+		}
+		return l.src[start:l.pos]
+	}
+	for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '_') {
+		l.pos++
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		l.pos++
+		for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '_') {
+			l.pos++
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == 'n' {
+		// BigInt suffix
+		l.pos++
+	}
+	return l.src[start:l.pos]
+}
+
+func (l *jsLexer) readRegExp() error {
+	start := l.pos
+	l.pos++
+	inClass := false
+	for {
+		if l.pos >= len(l.src) || l.src[l.pos] == '\n' {
+			return l.errorf(start, "unterminated regular expression")
+		}
+		c := l.src[l.pos]
+		l.pos++
+		switch {
+		case c == '\\':
+			l.pos++
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '/' && !inClass:
+			for l.pos < len(l.src) {
+				r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+				if !isIdentPart(r) {
+					break
+				}
+				l.pos += size
+			}
+			return nil
+		}
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r) || r == '\u200C' || r == '\u200D'
+}
+
+// jsValueParser decodes literal values from a token stream.
+type jsValueParser struct {
+	tokens []jsToken
+	pos    int
+}
+
+func (p *jsValueParser) peek() jsToken {
+	return p.tokens[p.pos]
+}
+
+func (p *jsValueParser) advance() jsToken {
+	tok := p.tokens[p.pos]
+	if tok.Kind != jsTokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *jsValueParser) isPunct(text string) bool {
+	tok := p.peek()
+	return tok.Kind == jsTokenPunct && tok.Text == text
+}
+
+func (p *jsValueParser) expect(text string) error {
+	tok := p.advance()
+	if tok.Kind != jsTokenPunct || tok.Text != text {
+		return unexpectedToken(tok, fmt.Sprintf("%q", text))
+	}
+	return nil
+}
+
+func unexpectedToken(tok jsToken, expected string) error {
+	if tok.Kind == jsTokenEOF {
+		return &JSSyntaxError{Offset: tok.Offset, Msg: "unexpected end of script, expected " + expected}
+	}
+	return &JSSyntaxError{Offset: tok.Offset, Msg: fmt.Sprintf("unexpected %q, expected %s", tok.Text, expected)}
+}
+
+// parseValue decodes a literal: objects, arrays, strings, numbers, booleans, null and undefined.
+// The minified forms !0, !1 and void 0 are understood.
+func (p *jsValueParser) parseValue() (*JSValue, error) {
+	tok := p.advance()
+	switch tok.Kind {
+	case jsTokenString:
+		return &JSValue{Kind: JSString, String: tok.Text, Offset: tok.Offset}, nil
+	case jsTokenNumber:
+		number, err := parseJSNumber(tok.Text)
+		if err != nil {
+			return nil, &JSSyntaxError{Offset: tok.Offset, Msg: err.Error()}
+		}
+		return &JSValue{Kind: JSNumber, Number: number, Offset: tok.Offset}, nil
+	case jsTokenIdent:
+		switch tok.Text {
+		case "true", "false":
+			return &JSValue{Kind: JSBool, Bool: tok.Text == "true", Offset: tok.Offset}, nil
+		case "null":
+			return &JSValue{Kind: JSNull, Offset: tok.Offset}, nil
+		case "undefined":
+			return &JSValue{Kind: JSUndefined, Offset: tok.Offset}, nil
+		case "Infinity", "NaN":
+			number, _ := strconv.ParseFloat(tok.Text, 64)
+			return &JSValue{Kind: JSNumber, Number: number, Offset: tok.Offset}, nil
+		case "void":
+			if _, err := p.parseValue(); err != nil {
+				return nil, err
+			}
+			return &JSValue{Kind: JSUndefined, Offset: tok.Offset}, nil
+		}
+	case jsTokenPunct:
+		switch tok.Text {
+		case "{":
+			return p.parseObject(tok)
+		case "[":
+			return p.parseArray(tok)
+		case "!":
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			return &JSValue{Kind: JSBool, Bool: !jsTruthy(value), Offset: tok.Offset}, nil
+		case "-", "+":
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			if value.Kind != JSNumber {
+				return nil, &JSSyntaxError{Offset: tok.Offset, Msg: "unary " + tok.Text + " on non number"}
+			}
+			if tok.Text == "-" {
+				value.Number = -value.Number
+			}
+			value.Offset = tok.Offset
+			return value, nil
+		}
+	}
+	return nil, unexpectedToken(tok, "a literal value")
+}
+
+func (p *jsValueParser) parseArray(open jsToken) (*JSValue, error) {
+	output := &JSValue{Kind: JSArray, Array: []*JSValue{}, Offset: open.Offset}
+	for {
+		if p.isPunct("]") {
+			p.advance()
+			return output, nil
+		}
+		if p.isPunct(",") {
+			// elision, e.g. [1,,2]
+			p.advance()
+			output.Array = append(output.Array, &JSValue{Kind: JSUndefined, Offset: p.peek().Offset})
+			continue
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		output.Array = append(output.Array, value)
+		if p.isPunct(",") {
+			p.advance()
+			continue
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return output, nil
+	}
+}
+
+func (p *jsValueParser) parseObject(open jsToken) (*JSValue, error) {
+	output := &JSValue{Kind: JSObject, Object: []JSProperty{}, Offset: open.Offset}
+	for {
+		if p.isPunct("}") {
+			p.advance()
+			return output, nil
+		}
+		tok := p.advance()
+		var key string
+		switch tok.Kind {
+		case jsTokenString, jsTokenIdent:
+			key = tok.Text
+		case jsTokenNumber:
+			number, err := parseJSNumber(tok.Text)
+			if err != nil {
+				return nil, &JSSyntaxError{Offset: tok.Offset, Msg: err.Error()}
+			}
+			key = strconv.FormatFloat(number, 'f', -1, 64)
+		default:
+			return nil, unexpectedToken(tok, "an object key")
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		output.Object = append(output.Object, JSProperty{Key: key, Value: value})
+		if p.isPunct(",") {
+			p.advance()
+			continue
+		}
+		if err := p.expect("}"); err != nil {
+			return nil, err
+		}
+		return output, nil
+	}
+}
+
+func parseJSNumber(text string) (float64, error) {
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "_", ""), "n")
+	if len(text) > 2 && text[0] == '0' {
+		base := 0
+		switch text[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 0 {
+			value, err := strconv.ParseUint(text[2:], base, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid number %q", text)
+			}
+			return float64(value), nil
+		}
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", text)
+	}
+	return value, nil
+}
+
+func jsTruthy(v *JSValue) bool {
+	switch v.Kind {
+	case JSBool:
+		return v.Bool
+	case JSNumber:
+		return v.Number != 0 && v.Number == v.Number
+	case JSString:
+		return v.String != ""
+	case JSArray, JSObject:
+		return true
+	}
+	return false
+}
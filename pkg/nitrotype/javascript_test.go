@@ -0,0 +1,290 @@
+package nitrotype
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func parseJS(src string) (*JSValue, error) {
+	tokens, err := tokenizeJS(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &jsValueParser{tokens: tokens}
+	return p.parseValue()
+}
+
+func TestTokenizeJSStrings(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "double quoted", src: `"a\nb\tc"`, want: "a\nb\tc"},
+		{name: "single quoted", src: `'it\'s "fine"'`, want: `it's "fine"`},
+		{name: "hex and unicode", src: `"\x41B\u{43}"`, want: "ABC"},
+		{name: "surrogate pair", src: `"\ud83d\ude00"`, want: "\U0001F600"},
+		{name: "line continuation", src: "\"a\\\nb\"", want: "ab"},
+		{name: "unknown escape", src: `"\/\q"`, want: "/q"},
+		{name: "comment marker", src: `"// not a comment"`, want: "// not a comment"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens, err := tokenizeJS(test.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tokens) != 2 || tokens[0].Kind != jsTokenString || tokens[0].Text != test.want {
+				t.Errorf("tokenizeJS(%s) = %+v, want string %q", test.src, tokens, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeJSErrors(t *testing.T) {
+	tests := map[string]string{
+		"unterminated string":   `"abc`,
+		"newline in string":     "'a\nb'",
+		"unterminated comment":  `/* abc`,
+		"unterminated template": "`a${b}",
+		"unterminated regexp":   "x = /ab\n/",
+	}
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := tokenizeJS(src)
+			var syntaxErr *JSSyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Errorf("tokenizeJS(%q) error = %v, want JSSyntaxError", src, err)
+			}
+		})
+	}
+}
+
+func TestTokenizeJSTemplatesAndRegExps(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		kinds []jsTokenKind
+	}{
+		{
+			name:  "template with substitution",
+			src:   "`a${b}c`",
+			kinds: []jsTokenKind{jsTokenTemplate, jsTokenIdent, jsTokenTemplate},
+		},
+		{
+			// The brace inside the substitution must not end it
+			name:  "object in substitution",
+			src:   "`a${{b:1}.b}c`",
+			kinds: []jsTokenKind{jsTokenTemplate, jsTokenPunct, jsTokenIdent, jsTokenPunct, jsTokenNumber, jsTokenPunct, jsTokenPunct, jsTokenIdent, jsTokenTemplate},
+		},
+		{
+			name:  "division",
+			src:   "a / b / 2",
+			kinds: []jsTokenKind{jsTokenIdent, jsTokenPunct, jsTokenIdent, jsTokenPunct, jsTokenNumber},
+		},
+		{
+			name:  "division after parenthesis",
+			src:   "(a) / 2",
+			kinds: []jsTokenKind{jsTokenPunct, jsTokenIdent, jsTokenPunct, jsTokenPunct, jsTokenNumber},
+		},
+		{
+			name:  "regexp after assignment",
+			src:   `x = /["']+/g`,
+			kinds: []jsTokenKind{jsTokenIdent, jsTokenPunct, jsTokenRegExp},
+		},
+		{
+			name:  "regexp after keyword",
+			src:   `return /a\/b[/]/i`,
+			kinds: []jsTokenKind{jsTokenIdent, jsTokenRegExp},
+		},
+		{
+			name:  "regexp in template substitution",
+			src:   "`${/}/.source}`",
+			kinds: []jsTokenKind{jsTokenTemplate, jsTokenRegExp, jsTokenPunct, jsTokenIdent, jsTokenTemplate},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens, err := tokenizeJS(test.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			kinds := make([]jsTokenKind, 0, len(tokens))
+			for _, tok := range tokens[:len(tokens)-1] {
+				kinds = append(kinds, tok.Kind)
+			}
+			if !reflect.DeepEqual(kinds, test.kinds) {
+				t.Errorf("tokenizeJS(%s) = %+v, want kinds %v", test.src, tokens, test.kinds)
+			}
+		})
+	}
+}
+
+func TestParseJSValue(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want interface{}
+	}{
+		{name: "not zero", src: "!0", want: true},
+		{name: "not one", src: "!1", want: false},
+		{name: "double negation", src: "!!\"\"", want: false},
+		{name: "void zero", src: "void 0", want: nil},
+		{name: "negative exponent", src: "-1.5e2", want: -150.0},
+		{name: "hex", src: "0x1F", want: 31.0},
+		{name: "separators", src: "1_000", want: 1000.0},
+		{name: "elision", src: "[1,,2]", want: []interface{}{1.0, nil, 2.0}},
+		{
+			name: "nested with trailing commas",
+			src:  `{a: [1, {b: [2, 3,],},], "c d": {e: !0,}, 7: 'x',}`,
+			want: map[string]interface{}{
+				"a":   []interface{}{1.0, map[string]interface{}{"b": []interface{}{2.0, 3.0}}},
+				"c d": map[string]interface{}{"e": true},
+				"7":   "x",
+			},
+		},
+		{name: "empty", src: "[{}, []]", want: []interface{}{map[string]interface{}{}, []interface{}{}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := parseJS(test.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := value.Interface(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parse(%s) = %#v, want %#v", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseJSValueErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{src: "{a: b}", want: `unexpected "b", expected a literal value (offset: 4)`},
+		{src: "[1 2]", want: `unexpected "2", expected "]" (offset: 3)`},
+		{src: "{a 1}", want: `unexpected "1", expected ":" (offset: 3)`},
+		{src: "{a: 1", want: `unexpected end of script, expected "}" (offset: 5)`},
+		{src: "-'a'", want: `unary - on non number (offset: 0)`},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			_, err := parseJS(test.src)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("parse(%s) error = %v, want %s", test.src, err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseBootstrapScriptEntryErrors(t *testing.T) {
+	script, err := ParseBootstrapScript([]byte(`window.NTGLOBALS=window.NTGLOBALS||{};[` +
+		`["CARS",[{carID:1,name:"Lambo"}]],` +
+		`["BROKEN",{a:someVariable}],` +
+		`["TOP_PLAYERS",{users:{abc:1},teams:{}}]` +
+		`].forEach(function(e){window.NTGLOBALS[e[0]]=e[1]});`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := script.Entry("CARS"); err != nil {
+		t.Errorf("Entry(CARS) error = %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		get  func() error
+		want string
+	}{
+		{
+			key:  "BROKEN",
+			get:  func() error { _, err := script.Entry("BROKEN"); return err },
+			want: `bootstrap entry "BROKEN": unexpected "someVariable", expected a literal value (offset: 86)`,
+		},
+		{
+			key:  "MISSING",
+			get:  func() error { _, err := script.Entry("MISSING"); return err },
+			want: `bootstrap entry "MISSING": entry not found`,
+		},
+		{
+			key:  "TOP_PLAYERS",
+			get:  func() error { _, _, err := script.TopPlayers(); return err },
+			want: `bootstrap entry "TOP_PLAYERS": users (row: 0): id "abc" is not a number`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			err := test.get()
+			var entryErr *BootstrapEntryError
+			if !errors.As(err, &entryErr) || entryErr.Key != test.key {
+				t.Fatalf("error = %v, want BootstrapEntryError for %s", err, test.key)
+			}
+			if err.Error() != test.want {
+				t.Errorf("error = %s, want %s", err, test.want)
+			}
+		})
+	}
+	if _, err := script.Entry("MISSING"); !errors.Is(err, ErrBootstrapEntryNotFound) {
+		t.Errorf("Entry(MISSING) error = %v, want ErrBootstrapEntryNotFound", err)
+	}
+}
+
+func TestParseBootstrapScriptDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries string
+		want    interface{}
+	}{
+		{
+			name:    "later match",
+			entries: `["CARS",[1]],["CARS",[2]]`,
+			want:    []interface{}{1.0},
+		},
+		{
+			name:    "later failure",
+			entries: `["CARS",[1]],["CARS",someVariable]`,
+			want:    []interface{}{1.0},
+		},
+		{
+			name:    "earlier failure",
+			entries: `["CARS",someVariable],["CARS",[2]]`,
+			want:    []interface{}{2.0},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			script, err := ParseBootstrapScript([]byte(`[` + test.entries + `].forEach(function(e){window.NTGLOBALS[e[0]]=e[1]});`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, err := script.Entry("CARS")
+			if err != nil {
+				t.Fatalf("Entry(CARS) error = %v", err)
+			}
+			if got := value.Interface(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Entry(CARS) = %#v, want %#v", got, test.want)
+			}
+			if keys := script.Keys(); !reflect.DeepEqual(keys, []string{"CARS"}) {
+				t.Errorf("Keys() = %q, want [CARS]", keys)
+			}
+		})
+	}
+}
+
+func TestBootstrapScriptGlobalsMarshal(t *testing.T) {
+	script, err := ParseBootstrapScript([]byte(`[["LIMITS",{max:Infinity,min:-Infinity,avg:NaN,count:3}]]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(script.Globals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"LIMITS":{"avg":null,"count":3,"max":null,"min":null}}`
+	if string(data) != want {
+		t.Errorf("Globals() = %s, want %s", data, want)
+	}
+}
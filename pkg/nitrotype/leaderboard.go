@@ -0,0 +1,282 @@
+package nitrotype
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+const (
+	// LeaderboardCacheKey is where the resolved profiles of top players and teams are cached.
+	LeaderboardCacheKey = "leaderboard_profiles"
+
+	// LeaderboardExpiration is how long resolved profiles are served without a successful refresh.
+	LeaderboardExpiration = 6 * time.Hour
+
+	// LeaderboardConcurrency is how many racer profiles a refresh looks up at once.
+	LeaderboardConcurrency = 4
+)
+
+// LeaderboardPlayer is a TOP_PLAYERS entry with the racer profile it refers to.
+// Resolved is false when the racer could not be identified.
+type LeaderboardPlayer struct {
+	RankItem
+	Resolved    bool    `json:"resolved"`
+	Username    string  `json:"username,omitempty"`
+	DisplayName string  `json:"displayName,omitempty"`
+	Tag         *string `json:"tag"`
+	Level       int     `json:"level,omitempty"`
+}
+
+// LeaderboardTeam is a TOP_TEAMS entry with the team it refers to.
+// Resolved is false when the team could not be identified.
+type LeaderboardTeam struct {
+	RankItem
+	Resolved bool   `json:"resolved"`
+	Tag      string `json:"tag,omitempty"`
+	TagColor string `json:"tagColor,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Members  int    `json:"members,omitempty"`
+}
+
+// LeaderboardProfiles maps user and team IDs to what is known about them.
+// Failures counts the lookups of the last refresh that failed, their profiles are kept from earlier refreshes.
+type LeaderboardProfiles struct {
+	Players   map[int]LeaderboardPlayer `json:"players"`
+	Teams     map[int]LeaderboardTeam   `json:"teams"`
+	UpdatedAt time.Time                 `json:"updatedAt"`
+	Failures  int                       `json:"failures"`
+}
+
+// LeaderboardResolver joins TOP_PLAYERS and TOP_TEAMS with racer and team profiles.
+// Nitro Type only links profiles by username and tag, so IDs are matched using the scoreboards
+// and team rosters, falling back to racer profiles for details the scoreboards lack.
+// Resolving takes many scrapes, so profiles are only resolved by Refresh, which is left to a schedule.
+type LeaderboardResolver struct {
+	fetcher      Fetcher
+	cacheManager *cache.Cache
+
+	// refreshing serialises refreshes so the slow lookups are not run twice at once
+	refreshing sync.Mutex
+}
+
+// NewLeaderboardResolver creates a resolver keeping its profiles in the cache.
+func NewLeaderboardResolver(fetcher Fetcher, cacheManager *cache.Cache) *LeaderboardResolver {
+	return &LeaderboardResolver{
+		fetcher:      fetcher,
+		cacheManager: cacheManager,
+	}
+}
+
+// Players returns TOP_PLAYERS in order with the resolved profiles.
+// ErrLeaderboardNotReady is returned until a refresh has succeeded.
+func (r *LeaderboardResolver) Players(ctx context.Context) ([]LeaderboardPlayer, error) {
+	profiles, ok := r.cached()
+	if !ok {
+		return nil, ErrLeaderboardNotReady
+	}
+	globals, err := r.globals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]LeaderboardPlayer, 0, len(globals.TopPlayers))
+	for _, item := range globals.TopPlayers {
+		row := profiles.Players[item.ID]
+		row.RankItem = item
+		output = append(output, row)
+	}
+	return output, nil
+}
+
+// Teams returns TOP_TEAMS in order with the resolved teams.
+// ErrLeaderboardNotReady is returned until a refresh has succeeded.
+func (r *LeaderboardResolver) Teams(ctx context.Context) ([]LeaderboardTeam, error) {
+	profiles, ok := r.cached()
+	if !ok {
+		return nil, ErrLeaderboardNotReady
+	}
+	globals, err := r.globals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]LeaderboardTeam, 0, len(globals.TopTeams))
+	for _, item := range globals.TopTeams {
+		row := profiles.Teams[item.ID]
+		row.RankItem = item
+		output = append(output, row)
+	}
+	return output, nil
+}
+
+// Refresh looks up every top player and team again and replaces the cached profiles.
+// Profiles that cannot be found this time are kept from the previous refresh.
+// The refresh fails without touching the cached profiles when no scoreboard could be scraped.
+func (r *LeaderboardResolver) Refresh(ctx context.Context) (*LeaderboardProfiles, error) {
+	r.refreshing.Lock()
+	defer r.refreshing.Unlock()
+
+	return r.refresh(ctx)
+}
+
+// globals returns NTGLOBALS, only failing to decode other sections than TOP_PLAYERS and TOP_TEAMS is ignored.
+func (r *LeaderboardResolver) globals(ctx context.Context) (*NTGlobals, error) {
+	globals, err := GetGlobals(ctx, r.fetcher)
+	var decodeErr *GlobalsDecodeError
+	if errors.As(err, &decodeErr) && decodeErr.Sections["TOP_PLAYERS"] == nil && decodeErr.Sections["TOP_TEAMS"] == nil {
+		return globals, nil
+	}
+	return globals, err
+}
+
+// refresh resolves the profiles, the caller must hold the refreshing lock.
+func (r *LeaderboardResolver) refresh(ctx context.Context) (*LeaderboardProfiles, error) {
+	globals, err := r.globals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := &LeaderboardProfiles{
+		Players: map[int]LeaderboardPlayer{},
+		Teams:   map[int]LeaderboardTeam{},
+	}
+	if previous, ok := r.cached(); ok {
+		for id, player := range previous.Players {
+			profiles.Players[id] = player
+		}
+		for id, team := range previous.Teams {
+			profiles.Teams[id] = team
+		}
+	}
+
+	// Scoreboards list IDs next to usernames and tags
+	var scoreboardErr error
+	scoreboards := 0
+	for _, kind := range ScoreboardKinds {
+		for _, timeframe := range ScoreboardTimeframes {
+			scoreboard, err := r.fetcher.GetScoreboard(ctx, kind, timeframe)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				scoreboardErr = err
+				profiles.Failures++
+				continue
+			}
+			scoreboards++
+			for _, row := range scoreboard.Rows {
+				if row.User != nil {
+					player := profiles.Players[row.User.UserID]
+					player.Resolved = true
+					player.Username = row.User.Username
+					player.DisplayName = row.User.DisplayName
+					player.Tag = row.User.Tag
+					profiles.Players[row.User.UserID] = player
+				}
+				if row.Team != nil {
+					team := profiles.Teams[row.Team.TeamID]
+					team.Resolved = true
+					team.Tag = row.Team.Tag
+					team.TagColor = row.Team.TagColor
+					team.Name = row.Team.Name
+					profiles.Teams[row.Team.TeamID] = team
+				}
+			}
+		}
+	}
+
+	if scoreboards == 0 {
+		return nil, fmt.Errorf("unable to scrape any scoreboard: %w", scoreboardErr)
+	}
+
+	// Team rosters give levels, and teams of top players that are not on a team scoreboard
+	tags := map[string]bool{}
+	for _, item := range globals.TopTeams {
+		if team, ok := profiles.Teams[item.ID]; ok && team.Tag != "" {
+			tags[strings.ToUpper(team.Tag)] = true
+		}
+	}
+	for _, item := range globals.TopPlayers {
+		if player, ok := profiles.Players[item.ID]; ok && player.Tag != nil && *player.Tag != "" {
+			tags[strings.ToUpper(*player.Tag)] = true
+		}
+	}
+	for tag := range tags {
+		team, err := r.fetcher.GetTeamData(ctx, tag)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if !errors.Is(err, ErrTeamNotFound) {
+				profiles.Failures++
+			}
+			continue
+		}
+		profiles.Teams[team.Info.TeamID] = LeaderboardTeam{
+			Resolved: true,
+			Tag:      team.Info.Tag,
+			TagColor: team.Info.TagColor,
+			Name:     team.Info.Name,
+			Members:  len(team.Members),
+		}
+		for _, member := range team.Members {
+			player := profiles.Players[member.UserID]
+			player.Resolved = true
+			player.Username = member.Username
+			player.DisplayName = member.DisplayName
+			player.Level = member.Level
+			tag := team.Info.Tag
+			player.Tag = &tag
+			profiles.Players[member.UserID] = player
+		}
+	}
+
+	// Racer profiles fill in whoever is still missing a level
+	var usernames []string
+	for _, item := range globals.TopPlayers {
+		player, ok := profiles.Players[item.ID]
+		if ok && player.Username != "" && player.Level == 0 {
+			usernames = append(usernames, player.Username)
+		}
+	}
+	results := GetPlayersData(ctx, usernames, PlayersOptions{Fetcher: r.fetcher, Concurrency: LeaderboardConcurrency})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			if !errors.Is(result.Err, ErrPlayerNotFound) {
+				profiles.Failures++
+			}
+			continue
+		}
+		racer := result.Player
+		player, ok := profiles.Players[racer.UserID]
+		if !ok || !strings.EqualFold(player.Username, racer.Username) {
+			continue
+		}
+		player.DisplayName = racer.DisplayName
+		player.Level = racer.Level
+		player.Tag = racer.Tag
+		profiles.Players[racer.UserID] = player
+	}
+
+	profiles.UpdatedAt = time.Now()
+	r.cacheManager.Set(LeaderboardCacheKey, profiles, LeaderboardExpiration)
+	return profiles, nil
+}
+
+func (r *LeaderboardResolver) cached() (*LeaderboardProfiles, bool) {
+	cacheSource, found := r.cacheManager.Get(LeaderboardCacheKey)
+	if !found {
+		return nil, false
+	}
+	profiles, ok := cacheSource.(*LeaderboardProfiles)
+	return profiles, ok && profiles != nil
+}
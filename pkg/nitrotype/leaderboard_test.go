@@ -0,0 +1,89 @@
+package nitrotype_test
+
+import (
+	"context"
+	"errors"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"nt-bootstrap-scraper/pkg/nitrotype/nitrotypetest"
+	"testing"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// scoreboardOutage fails every scoreboard scrape while down is set.
+type scoreboardOutage struct {
+	nitrotype.Fetcher
+	down bool
+}
+
+func (f *scoreboardOutage) GetScoreboard(ctx context.Context, kind nitrotype.ScoreboardKind, timeframe nitrotype.ScoreboardTimeframe) (*nitrotype.NTScoreboard, error) {
+	if f.down {
+		return nil, nitrotype.ErrUpstreamError
+	}
+	return f.Fetcher.GetScoreboard(ctx, kind, timeframe)
+}
+
+func TestLeaderboardResolver(t *testing.T) {
+	server := nitrotypetest.NewServer()
+	defer server.Close()
+
+	cacheManager := cache.New(cache.NoExpiration, cache.NoExpiration)
+	fetcher := &scoreboardOutage{Fetcher: server.Fetcher()}
+	resolver := nitrotype.NewLeaderboardResolver(fetcher, cacheManager)
+	ctx := context.Background()
+
+	// Nothing is scraped on request, the leaderboard waits for a refresh
+	if _, err := resolver.Players(ctx); !errors.Is(err, nitrotype.ErrLeaderboardNotReady) {
+		t.Fatalf("Players() before refresh error = %v, want ErrLeaderboardNotReady", err)
+	}
+
+	profiles, err := resolver.Refresh(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profiles.Failures != 0 {
+		t.Errorf("Failures = %d, want 0", profiles.Failures)
+	}
+
+	players, err := resolver.Players(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 2 || players[0].Username != "speedy" || players[0].Level != 120 || !players[1].Resolved || players[1].Level != 8 {
+		t.Errorf("Players() = %+v", players)
+	}
+	teams, err := resolver.Teams(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(teams) != 1 || teams[0].Tag != "FAST" || teams[0].Members != 3 {
+		t.Errorf("Teams() = %+v", teams)
+	}
+
+	// A refresh that cannot reach any scoreboard keeps the previous profiles
+	fetcher.down = true
+	if _, err := resolver.Refresh(ctx); !errors.Is(err, nitrotype.ErrUpstreamError) {
+		t.Errorf("Refresh() during outage error = %v, want ErrUpstreamError", err)
+	}
+	players, err = resolver.Players(ctx)
+	if err != nil || len(players) != 2 || players[0].Username != "speedy" {
+		t.Errorf("Players() after failed refresh = %+v, %v", players, err)
+	}
+}
+
+func TestLeaderboardResolverNeverRefreshed(t *testing.T) {
+	server := nitrotypetest.NewServer()
+	defer server.Close()
+	server.SetUnavailable(nitrotype.PageChallenge)
+
+	cacheManager := cache.New(cache.NoExpiration, cache.NoExpiration)
+	resolver := nitrotype.NewLeaderboardResolver(server.Fetcher(), cacheManager)
+	ctx := context.Background()
+
+	if _, err := resolver.Refresh(ctx); !errors.Is(err, nitrotype.ErrBotChallenge) {
+		t.Errorf("Refresh() error = %v, want ErrBotChallenge", err)
+	}
+	if _, err := resolver.Teams(ctx); !errors.Is(err, nitrotype.ErrLeaderboardNotReady) {
+		t.Errorf("Teams() error = %v, want ErrLeaderboardNotReady", err)
+	}
+}
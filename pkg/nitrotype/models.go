@@ -3,6 +3,9 @@ package nitrotype
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 )
 
 type NTPlayerLegacy map[string]interface{}
@@ -94,6 +97,11 @@ type NTPlayerCar struct {
 }
 
 func (c *NTPlayerCar) UnmarshalJSON(bs []byte) error {
+	// Accept our own marshalled output so saved profiles can be read back
+	if len(bs) > 0 && bs[0] == '{' {
+		type ntPlayerCar NTPlayerCar
+		return json.Unmarshal(bs, (*ntPlayerCar)(c))
+	}
 	data := []interface{}{}
 	err := json.Unmarshal(bs, &data)
 	if err != nil {
@@ -122,8 +130,295 @@ func (c *NTPlayerCar) UnmarshalJSON(bs []byte) error {
 	return nil
 }
 
+// Team member roles found on the NT Team page.
+const (
+	TeamRoleCaptain = "captain"
+	TeamRoleOfficer = "officer"
+	TeamRoleMember  = "member"
+)
+
+// NTTeam contains data from the NT Team page.
+type NTTeam struct {
+	Info     NTTeamInfo     `json:"info"`
+	Members  []NTTeamMember `json:"members"`
+	Officers []NTTeamMember `json:"officers"`
+	MOTD     *NTTeamMOTD    `json:"motd"`
+	Stats    []NTTeamStats  `json:"stats"`
+}
+
+// NTTeamInfo contains the team details and joining requirements.
+type NTTeamInfo struct {
+	TeamID            int    `json:"teamID"`
+	UserID            int    `json:"userID"`
+	Tag               string `json:"tag"`
+	TagColor          string `json:"tagColor"`
+	Name              string `json:"name"`
+	Enrollment        string `json:"enrollment"`
+	MinLevel          int    `json:"minLevel"`
+	MinRaces          int    `json:"minRaces"`
+	MinSpeed          int    `json:"minSpeed"`
+	AutoRemove        int    `json:"autoRemove"`
+	OtherRequirements string `json:"otherRequirements"`
+	Members           int    `json:"members"`
+	ProfileViews      int    `json:"profileViews"`
+	LastActivity      int    `json:"lastActivity"`
+	CreatedStamp      int    `json:"createdStamp"`
+}
+
+// NTTeamMember contains a racer on the team roster along with their team race stats.
+type NTTeamMember struct {
+	UserID      int    `json:"userID"`
+	Username    string `json:"username"`
+	DisplayName string `json:"displayName"`
+	Membership  string `json:"membership"`
+	Title       string `json:"title"`
+	Level       int    `json:"level"`
+	CarID       int    `json:"carID"`
+	CarHueAngle int    `json:"carHueAngle"`
+	Role        string `json:"role"`
+	TeamJoined  int    `json:"teamJoined"`
+	LastLogin   int    `json:"lastLogin"`
+	Played      int    `json:"played"`
+	Typed       int    `json:"typed"`
+	Errs        int    `json:"errs"`
+	Secs        int    `json:"secs"`
+}
+
+// setOfficers fills Officers from the roles on the roster, captain first.
+func (t *NTTeam) setOfficers() {
+	t.Officers = []NTTeamMember{}
+	for _, member := range t.Members {
+		if member.Role == TeamRoleCaptain {
+			t.Officers = append(t.Officers, member)
+		}
+	}
+	for _, member := range t.Members {
+		if member.Role == TeamRoleOfficer {
+			t.Officers = append(t.Officers, member)
+		}
+	}
+}
+
+// IsOfficer reports whether the member is the captain or an officer.
+func (m NTTeamMember) IsOfficer() bool {
+	return m.Role == TeamRoleCaptain || m.Role == TeamRoleOfficer
+}
+
+// NTTeamMOTD is the team message of the day.
+type NTTeamMOTD struct {
+	MOTD         string `json:"motd"`
+	Username     string `json:"username"`
+	LastModified int    `json:"lastModified"`
+}
+
+// NTTeamStats contains the team race totals for a scoreboard period (daily, weekly, season...).
+type NTTeamStats struct {
+	Board  string `json:"board"`
+	Played int    `json:"played"`
+	Typed  int    `json:"typed"`
+	Errs   int    `json:"errs"`
+	Secs   int    `json:"secs"`
+	Stamp  int    `json:"stamp"`
+}
+
+// Speed is the average words per minute, counting five characters as a word.
+func (s NTTeamStats) Speed() float64 {
+	if s.Secs <= 0 {
+		return 0
+	}
+	return float64(s.Typed) / 5 / (float64(s.Secs) / 60)
+}
+
+// ScoreboardKind is whether a scoreboard ranks racers or teams.
+type ScoreboardKind string
+
+const (
+	ScoreboardIndividual ScoreboardKind = "individual"
+	ScoreboardTeam       ScoreboardKind = "team"
+)
+
+// ScoreboardKinds lists every scoreboard kind.
+var ScoreboardKinds = []ScoreboardKind{ScoreboardIndividual, ScoreboardTeam}
+
+// ScoreboardTimeframe is the period a scoreboard covers.
+type ScoreboardTimeframe string
+
+const (
+	ScoreboardDaily   ScoreboardTimeframe = "daily"
+	ScoreboardWeekly  ScoreboardTimeframe = "weekly"
+	ScoreboardMonthly ScoreboardTimeframe = "monthly"
+	ScoreboardSeason  ScoreboardTimeframe = "season"
+)
+
+// ScoreboardTimeframes lists every scoreboard timeframe.
+var ScoreboardTimeframes = []ScoreboardTimeframe{ScoreboardDaily, ScoreboardWeekly, ScoreboardMonthly, ScoreboardSeason}
+
+// ValidateScoreboard returns ErrInvalidScoreboard unless kind and timeframe name a board on the site.
+func ValidateScoreboard(kind ScoreboardKind, timeframe ScoreboardTimeframe) error {
+	validKind := false
+	for _, item := range ScoreboardKinds {
+		validKind = validKind || item == kind
+	}
+	validTimeframe := false
+	for _, item := range ScoreboardTimeframes {
+		validTimeframe = validTimeframe || item == timeframe
+	}
+	if !validKind || !validTimeframe {
+		return &ScrapeError{Kind: ErrInvalidScoreboard, Err: fmt.Errorf("%q/%q", kind, timeframe)}
+	}
+	return nil
+}
+
+// NTScoreboard contains a scoreboard page.
+type NTScoreboard struct {
+	Kind      ScoreboardKind      `json:"kind"`
+	Timeframe ScoreboardTimeframe `json:"timeframe"`
+	Rows      []NTScoreboardRow   `json:"rows"`
+}
+
+// NTScoreboardRow is a ranked racer or team, only one of User or Team is set.
+type NTScoreboardRow struct {
+	Rank   int               `json:"rank"`
+	User   *NTScoreboardUser `json:"user,omitempty"`
+	Team   *NTScoreboardTeam `json:"team,omitempty"`
+	Races  int               `json:"races"`
+	Speed  float64           `json:"speed"`
+	Points int64             `json:"points"`
+}
+
+// NTScoreboardUser identifies a racer on a scoreboard.
+type NTScoreboardUser struct {
+	UserID      int     `json:"userID"`
+	Username    string  `json:"username"`
+	DisplayName string  `json:"displayName"`
+	Tag         *string `json:"tag"`
+	TagColor    *string `json:"tagColor"`
+}
+
+// NTScoreboardTeam identifies a team on a scoreboard.
+type NTScoreboardTeam struct {
+	TeamID   int    `json:"teamID"`
+	Tag      string `json:"tag"`
+	TagColor string `json:"tagColor"`
+	Name     string `json:"name"`
+}
+
+// ntScoreboardPage is SCOREBOARD_INFO as embedded on the scoreboard page.
+type ntScoreboardPage struct {
+	Kind      ScoreboardKind      `json:"kind"`
+	Timeframe ScoreboardTimeframe `json:"timeframe"`
+	Scores    []struct {
+		Rank        int     `json:"rank"`
+		UserID      int     `json:"userID"`
+		Username    string  `json:"username"`
+		DisplayName string  `json:"displayName"`
+		TeamID      int     `json:"teamID"`
+		Tag         *string `json:"tag"`
+		TagColor    *string `json:"tagColor"`
+		Name        string  `json:"name"`
+		Played      int     `json:"played"`
+		Typed       int64   `json:"typed"`
+		Secs        int64   `json:"secs"`
+		Points      int64   `json:"points"`
+	} `json:"scores"`
+}
+
+// scoreboard converts the page data into typed rows, computing speed from characters typed over time.
+func (p ntScoreboardPage) scoreboard() *NTScoreboard {
+	output := &NTScoreboard{
+		Kind:      p.Kind,
+		Timeframe: p.Timeframe,
+		Rows:      make([]NTScoreboardRow, 0, len(p.Scores)),
+	}
+	for _, score := range p.Scores {
+		row := NTScoreboardRow{
+			Rank:   score.Rank,
+			Races:  score.Played,
+			Points: score.Points,
+		}
+		if score.Secs > 0 {
+			row.Speed = float64(score.Typed) / 5 / (float64(score.Secs) / 60)
+		}
+		if p.Kind == ScoreboardTeam {
+			row.Team = &NTScoreboardTeam{
+				TeamID: score.TeamID,
+				Name:   score.Name,
+			}
+			if score.Tag != nil {
+				row.Team.Tag = *score.Tag
+			}
+			if score.TagColor != nil {
+				row.Team.TagColor = *score.TagColor
+			}
+		} else {
+			row.User = &NTScoreboardUser{
+				UserID:      score.UserID,
+				Username:    score.Username,
+				DisplayName: score.DisplayName,
+				Tag:         score.Tag,
+				TagColor:    score.TagColor,
+			}
+		}
+		output.Rows = append(output.Rows, row)
+	}
+	return output
+}
+
 type NTGlobalsLegacy map[string]interface{}
 
+// Decode converts the raw NTGLOBALS into the typed NTGlobals.
+// Each section is decoded on its own, so a section that no longer matches the model is left empty
+// and reported in a *GlobalsDecodeError returned alongside the sections that did decode.
+func (g NTGlobalsLegacy) Decode() (*NTGlobals, error) {
+	var output NTGlobals
+	failed := map[string]error{}
+
+	value := reflect.ValueOf(&output).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name, _ := jsonFieldName(value.Type().Field(i))
+		section, ok := g[name]
+		if name == "" || !ok {
+			continue
+		}
+		data, err := json.Marshal(section)
+		if err == nil {
+			err = json.Unmarshal(data, value.Field(i).Addr().Interface())
+		}
+		if err != nil {
+			value.Field(i).Set(reflect.Zero(value.Field(i).Type()))
+			failed[name] = err
+		}
+	}
+	if len(failed) > 0 {
+		return &output, &GlobalsDecodeError{Sections: failed}
+	}
+	return &output, nil
+}
+
+// GlobalsDecodeError lists the NTGLOBALS sections that could not be decoded into the typed model.
+// It matches ErrGlobalsDecode with errors.Is.
+type GlobalsDecodeError struct {
+	Sections map[string]error
+}
+
+func (e *GlobalsDecodeError) Error() string {
+	names := make([]string, 0, len(e.Sections))
+	for name := range e.Sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		messages = append(messages, fmt.Sprintf("%s: %s", name, e.Sections[name]))
+	}
+	return fmt.Sprintf("unable to decode NTGLOBALS sections: %s", strings.Join(messages, "; "))
+}
+
+func (e *GlobalsDecodeError) Is(target error) bool {
+	return target == ErrGlobalsDecode
+}
+
+// NTGlobals contains the typed NTGLOBALS data, including the ordered Top Players and Teams.
 type NTGlobals struct {
 	ActionSeasons []ActiveSeason `json:"ACTIVE_SEASONS"`
 	Achievements  struct {
@@ -152,12 +447,13 @@ type NTGlobals struct {
 	ScoreboardRankMimimums map[string]ScoreboardRankMimimums `json:"SCOREBOARD_RANK_MINIMUMS"`
 	LootConfig             map[string]LootConfig             `json:"LOOT_CONFIG"`
 	ChallengeTypes         map[string][]string               `json:"CHALLENGE_TYPES"`
-	TopPlayers             []RankItem
-	TopTeams               []RankItem
+	TopPlayers             []RankItem                        `json:"TOP_PLAYERS"`
+	TopTeams               []RankItem                        `json:"TOP_TEAMS"`
 }
 
+// ActiveSeason is an ACTIVE_SEASONS entry, seasonID is the key achievements refer to the season by.
 type ActiveSeason struct {
-	SeasonID             int    `json:"sessionID"`
+	SeasonID             int    `json:"seasonID"`
 	Name                 string `json:"name"`
 	StartStamp           int64  `json:"startStamp"`
 	EndStamp             int64  `json:"endStamp"`
@@ -353,6 +649,21 @@ type ScoreboardRankMimimums struct {
 	Daily   int `json:"daily"`
 }
 
+// For returns the minimum for a scoreboard timeframe.
+func (m ScoreboardRankMimimums) For(timeframe ScoreboardTimeframe) int {
+	switch timeframe {
+	case ScoreboardDaily:
+		return m.Daily
+	case ScoreboardWeekly:
+		return m.Weekly
+	case ScoreboardMonthly:
+		return m.Monthly
+	case ScoreboardSeason:
+		return m.Season
+	}
+	return 0
+}
+
 type LootConfig struct {
 	Defaults    []int  `json:"defaults"`
 	MaxEquipped int    `json:"maxEquipped"`
@@ -1,12 +1,13 @@
 package nitrotype
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"net/http"
+	"net/url"
 	"regexp"
-	"strconv"
 	"time"
 
 	"github.com/chromedp/cdproto/dom"
@@ -14,34 +15,44 @@ import (
 	"github.com/chromedp/chromedp"
 )
 
+const (
+	// DefaultBaseURL is the origin of the live Nitro Type site.
+	DefaultBaseURL = "https://www.nitrotype.com"
+
+	// UserAgent is the browser identity used when requesting pages from Nitro Type.
+	UserAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/97.0.4692.99 Safari/537.36"
+)
+
 var (
-	TopPlayerRegExp          = regexp.MustCompile(`\["TOP_PLAYERS",\{"users":(.*?),"teams":(.*?)\],`)
-	TopPlayerMapRegExp       = regexp.MustCompile(`"([0-9]+)":([0-9]+)`)
 	UserProfileExtractRegExp = regexp.MustCompile(`(?m)RACER_INFO: (.*),$`)
-	ErrPlayerNotFound        = fmt.Errorf("player not found")
+	TeamInfoExtractRegExp    = regexp.MustCompile(`(?m)TEAM_INFO: (.*),$`)
+	ScoreboardExtractRegExp  = regexp.MustCompile(`(?m)SCOREBOARD_INFO: (.*),$`)
 )
 
 // GetBootstrapData retrives the NTGLOBALS variable from Nitro Type.
 // This function will also manually sort in Top Players and Teams.
 func GetBootstrapData(ctx context.Context) (*NTGlobalsLegacy, error) {
-	// Setup Chrome
-	ctx, cancel := chromedp.NewExecAllocator(ctx,
-		chromedp.UserAgent("Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/97.0.4692.99 Safari/537.36"),
-		chromedp.NoFirstRun,
-		chromedp.NoDefaultBrowserCheck,
-		chromedp.NoSandbox,
-		chromedp.Headless,
-		chromedp.DisableGPU,
-	)
-	defer cancel()
+	return ChromeFetcher{}.GetBootstrapData(ctx)
+}
 
-	ctx, cancel = chromedp.NewContext(
-		ctx,
-		chromedp.WithLogf(log.Printf),
-	)
-	defer cancel()
+// GetPlayerData fetches the RACER_INFO data from racer profile page.
+func GetPlayerData(ctx context.Context, username string) (*NTPlayer, error) {
+	return ChromeFetcher{}.GetPlayerData(ctx, username)
+}
+
+// GetTeamData fetches the TEAM_INFO data from the team page.
+func GetTeamData(ctx context.Context, tag string) (*NTTeam, error) {
+	return ChromeFetcher{}.GetTeamData(ctx, tag)
+}
 
-	ctx, cancel = context.WithTimeout(ctx, 120*time.Second)
+// GetScoreboard fetches a racer or team scoreboard for the given timeframe.
+func GetScoreboard(ctx context.Context, kind ScoreboardKind, timeframe ScoreboardTimeframe) (*NTScoreboard, error) {
+	return ChromeFetcher{}.GetScoreboard(ctx, kind, timeframe)
+}
+
+// getBootstrapDataChrome retrives the NTGLOBALS variable from the given site using a Chrome tab.
+func getBootstrapDataChrome(ctx context.Context, baseURL string) (*NTGlobalsLegacy, error) {
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
 	defer cancel()
 
 	// Find bootstrap.js
@@ -51,11 +62,26 @@ func GetBootstrapData(ctx context.Context) (*NTGlobalsLegacy, error) {
 		ntGlobals NTGlobalsLegacy
 	)
 
-	err := chromedp.Run(ctx,
-		chromedp.Navigate("https://www.nitrotype.com/"),
+	// Recognise challenge and maintenance pages before waiting on content that never comes
+	var homepage string
+	resp, err := chromedp.RunResponse(ctx, chromedp.Navigate(baseURL+"/"))
+	if err != nil {
+		return nil, err
+	}
+	if err := chromedp.Run(ctx, chromedp.OuterHTML("html", &homepage, chromedp.ByQuery)); err != nil {
+		return nil, err
+	}
+	if err := checkPage(int(resp.Status), chromeHeaders(resp.Headers), []byte(homepage), baseURL+"/"); err != nil {
+		return nil, err
+	}
+
+	err = chromedp.Run(ctx,
 		chromedp.WaitReady("#root"),
-		chromedp.Evaluate("window.NTGLOBALS", &ntGlobals, chromedp.EvalAsValue),
+		chromedp.Evaluate("window.NTGLOBALS || null", &ntGlobals, chromedp.EvalAsValue),
 		chromedp.ActionFunc(func(ctx context.Context) error {
+			if len(ntGlobals) == 0 {
+				return ErrNTGlobalsMissing
+			}
 			node, err := dom.GetDocument().Do(ctx)
 			if err != nil {
 				return err
@@ -64,6 +90,9 @@ func GetBootstrapData(ctx context.Context) (*NTGlobalsLegacy, error) {
 			if err != nil {
 				return err
 			}
+			if bootstrapNode == 0 {
+				return ErrBootstrapScriptNotFound
+			}
 			attributes, err := dom.GetAttributes(bootstrapNode).Do(ctx)
 			if err != nil {
 				return err
@@ -82,9 +111,12 @@ func GetBootstrapData(ctx context.Context) (*NTGlobalsLegacy, error) {
 
 			// Grab Bootstrap Source to manually parse in the Top Players+Teams
 			if bootstrapSrc == "" {
-				return fmt.Errorf("bootstrap.js not found")
+				return ErrBootstrapScriptNotFound
+			}
+			bootstrapSrc, err = resolveURL(baseURL, bootstrapSrc)
+			if err != nil {
+				return err
 			}
-			bootstrapSrc = "https://www.nitrotype.com" + bootstrapSrc
 
 			return nil
 		}),
@@ -115,7 +147,11 @@ func GetBootstrapData(ctx context.Context) (*NTGlobalsLegacy, error) {
 		return nil, err
 	}
 
-	<-downloadComplete
+	select {
+	case <-downloadComplete:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 
 	// Get the downloaded bytes for the request id
 	var downloadBytes []byte
@@ -126,94 +162,96 @@ func GetBootstrapData(ctx context.Context) (*NTGlobalsLegacy, error) {
 		return nil, err
 	}
 
-	data := string(downloadBytes)
-
-	// Extract Top Players and Teams in ordered fashion
-	topPlayerData := TopPlayerRegExp.FindStringSubmatch(data)
-	if len(topPlayerData) != 3 {
-		return nil, fmt.Errorf("unable to parse top players")
+	script, err := ParseBootstrapScript(downloadBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := setTopPlayers(ntGlobals, script); err != nil {
+		return nil, err
 	}
 
-	var topPlayers []RankItem
-	playerData := TopPlayerMapRegExp.FindAllStringSubmatch(topPlayerData[1], -1)
-	for i, r := range playerData {
-		userID, err := strconv.Atoi(r[1])
-		if err != nil {
-			return nil, fmt.Errorf("unable to parse top player id (row: %d): %w", i, err)
-		}
-		position, err := strconv.Atoi(r[2])
-		if err != nil {
-			return nil, fmt.Errorf("unable to parse top player position (row: %d): %w", i, err)
-		}
-
-		topPlayers = append(topPlayers, RankItem{
-			Index:    i + 1,
-			ID:       userID,
-			Position: position,
-		})
-	}
-	var topTeams []RankItem
-	teamData := TopPlayerMapRegExp.FindAllStringSubmatch(topPlayerData[2], -1)
-	for i, r := range teamData {
-		teamID, err := strconv.Atoi(r[1])
-		if err != nil {
-			return nil, fmt.Errorf("unable to parse top team id (row: %d): %w", i, err)
-		}
-		position, err := strconv.Atoi(r[2])
-		if err != nil {
-			return nil, fmt.Errorf("unable to parse top team position (row: %d): %w", i, err)
-		}
+	return &ntGlobals, nil
+}
 
-		topTeams = append(topTeams, RankItem{
-			Index:    i + 1,
-			ID:       teamID,
-			Position: position,
-		})
+// setTopPlayers replaces TOP_PLAYERS in NTGLOBALS with the ordered Top Players and Teams found in bootstrap.js.
+func setTopPlayers(ntGlobals NTGlobalsLegacy, script *BootstrapScript) error {
+	topPlayers, topTeams, err := script.TopPlayers()
+	if err != nil {
+		return &ScrapeError{Kind: ErrTopPlayersParse, Err: err}
 	}
 	delete(ntGlobals, "TOP_PLAYERS")
 	ntGlobals["TOP_PLAYERS"] = topPlayers
 	ntGlobals["TOP_TEAMS"] = topTeams
-	// ntGlobals.TopPlayers = topPlayers
-	// ntGlobals.TopTeams = topTeams
 
-	return &ntGlobals, nil
+	return nil
 }
 
-// GetPlayerData fetches the RACER_INFO data from racer profile page.
-func GetPlayerData(ctx context.Context, username string) (*NTPlayer, error) {
-	// Setup Chrome
-	ctx, cancel := chromedp.NewExecAllocator(ctx,
-		chromedp.UserAgent("Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/97.0.4692.99 Safari/537.36"),
-		chromedp.NoFirstRun,
-		chromedp.NoDefaultBrowserCheck,
-		chromedp.NoSandbox,
-		chromedp.Headless,
-		chromedp.DisableGPU,
-	)
+// getPlayerDataChrome fetches the RACER_INFO data from racer profile page using a Chrome tab.
+func getPlayerDataChrome(ctx context.Context, baseURL string, username string) (*NTPlayer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
 	defer cancel()
 
-	ctx, cancel = chromedp.NewContext(
-		ctx,
-		chromedp.WithLogf(log.Printf),
-	)
+	downloadBytes, err := getPageSourceChrome(ctx, baseURL+"/racer/"+url.PathEscape(username))
+	if err != nil {
+		if isUndefinedValue(err) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, err
+	}
+	return parsePlayerPage(downloadBytes)
+}
 
+// getTeamDataChrome fetches the TEAM_INFO data from the team page using a Chrome tab.
+func getTeamDataChrome(ctx context.Context, baseURL string, tag string) (*NTTeam, error) {
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
 	defer cancel()
 
-	ctx, cancel = context.WithTimeout(ctx, 120*time.Second)
+	downloadBytes, err := getPageSourceChrome(ctx, baseURL+"/team/"+url.PathEscape(tag))
+	if err != nil {
+		if isUndefinedValue(err) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, err
+	}
+	return parseTeamPage(downloadBytes)
+}
+
+// getScoreboardChrome fetches the SCOREBOARD_INFO data from the scoreboard page using a Chrome tab.
+func getScoreboardChrome(ctx context.Context, baseURL string, kind ScoreboardKind, timeframe ScoreboardTimeframe) (*NTScoreboard, error) {
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
 	defer cancel()
 
-	profileURL := "https://www.nitrotype.com/racer/" + username
+	downloadBytes, err := getPageSourceChrome(ctx, scoreboardURL(baseURL, kind, timeframe))
+	if err != nil {
+		return nil, err
+	}
+	return parseScoreboardPage(downloadBytes, kind, timeframe)
+}
 
+// scoreboardURL is the page listing a scoreboard.
+func scoreboardURL(baseURL string, kind ScoreboardKind, timeframe ScoreboardTimeframe) string {
+	return baseURL + "/scoreboard/" + string(kind) + "/" + string(timeframe)
+}
+
+// getPageSourceChrome downloads the html of a page as served, before any scripts run.
+func getPageSourceChrome(ctx context.Context, pageURL string) ([]byte, error) {
 	// Setup download
-	var requestID network.RequestID
+	var (
+		requestID network.RequestID
+		response  *network.Response
+	)
 	downloadComplete := make(chan bool)
 
 	chromedp.ListenTarget(ctx, func(v interface{}) {
 		switch ev := v.(type) {
 		case *network.EventRequestWillBeSent:
-			if ev.Request.URL == profileURL {
+			if ev.Request.URL == pageURL {
 				requestID = ev.RequestID
 			}
+		case *network.EventResponseReceived:
+			if ev.RequestID == requestID {
+				response = ev.Response
+			}
 		case *network.EventLoadingFinished:
 			if ev.RequestID == requestID {
 				close(downloadComplete)
@@ -221,15 +259,16 @@ func GetPlayerData(ctx context.Context, username string) (*NTPlayer, error) {
 		}
 	})
 
-	err := chromedp.Run(ctx, chromedp.Navigate("view-source:"+profileURL))
+	err := chromedp.Run(ctx, chromedp.Navigate("view-source:"+pageURL))
 	if err != nil {
-		if err.Error() == "encountered an undefined value" {
-			return nil, ErrPlayerNotFound
-		}
 		return nil, err
 	}
 
-	<-downloadComplete
+	select {
+	case <-downloadComplete:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 
 	// Get the downloaded bytes for the request id
 	var downloadBytes []byte
@@ -240,7 +279,18 @@ func GetPlayerData(ctx context.Context, username string) (*NTPlayer, error) {
 		return nil, err
 	}
 
-	matches := UserProfileExtractRegExp.FindSubmatch(downloadBytes)
+	if response != nil {
+		if err := checkPage(int(response.Status), chromeHeaders(response.Headers), downloadBytes, pageURL); err != nil {
+			return nil, err
+		}
+	}
+
+	return downloadBytes, nil
+}
+
+// parsePlayerPage extracts RACER_INFO from the racer profile page html.
+func parsePlayerPage(page []byte) (*NTPlayer, error) {
+	matches := UserProfileExtractRegExp.FindSubmatch(page)
 	if len(matches) != 2 {
 		return nil, ErrPlayerNotFound
 	}
@@ -252,3 +302,46 @@ func GetPlayerData(ctx context.Context, username string) (*NTPlayer, error) {
 
 	return &output, nil
 }
+
+// parseScoreboardPage extracts SCOREBOARD_INFO from the scoreboard page html.
+func parseScoreboardPage(page []byte, kind ScoreboardKind, timeframe ScoreboardTimeframe) (*NTScoreboard, error) {
+	matches := ScoreboardExtractRegExp.FindSubmatch(page)
+	if len(matches) != 2 {
+		return nil, &ScrapeError{Kind: ErrScoreboardParse, Err: fmt.Errorf("SCOREBOARD_INFO not found")}
+	}
+
+	var output ntScoreboardPage
+	if err := json.Unmarshal(matches[1], &output); err != nil {
+		return nil, &ScrapeError{Kind: ErrScoreboardParse, Err: err}
+	}
+	if output.Kind != kind || output.Timeframe != timeframe {
+		return nil, &ScrapeError{Kind: ErrScoreboardParse, Err: fmt.Errorf("expected %s/%s board, found %s/%s", kind, timeframe, output.Kind, output.Timeframe)}
+	}
+
+	return output.scoreboard(), nil
+}
+
+// parseTeamPage extracts TEAM_INFO from the team page html.
+func parseTeamPage(page []byte) (*NTTeam, error) {
+	matches := TeamInfoExtractRegExp.FindSubmatch(page)
+	if len(matches) != 2 || bytes.Equal(matches[1], []byte("null")) {
+		return nil, ErrTeamNotFound
+	}
+
+	var output NTTeam
+	if err := json.Unmarshal(matches[1], &output); err != nil {
+		return nil, err
+	}
+	output.setOfficers()
+
+	return &output, nil
+}
+
+// chromeHeaders converts response headers reported by Chrome.
+func chromeHeaders(headers network.Headers) http.Header {
+	output := http.Header{}
+	for key, value := range headers {
+		output.Set(key, fmt.Sprint(value))
+	}
+	return output
+}
@@ -0,0 +1,125 @@
+package nitrotype
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// The pages in testdata follow the layout of the live site, window.NTROUTER with one entry per line.
+// They were written by hand rather than captured, replace them with captured pages when the layout is confirmed.
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseTeamPage(t *testing.T) {
+	team, err := parseTeamPage(readTestdata(t, "team_page.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if team.Info.TeamID != 7 || team.Info.Tag != "FAST" || team.Info.Name != "Fast Fingers" || team.Info.OtherRequirements != "Race daily! <3" {
+		t.Errorf("Info = %+v", team.Info)
+	}
+	if len(team.Members) != 3 || team.Members[1].DisplayName != "Speedy ⚡" {
+		t.Errorf("Members = %+v", team.Members)
+	}
+	// The captain comes first even when listed after an officer
+	if len(team.Officers) != 2 || team.Officers[0].Username != "speedy" || team.Officers[1].Username != "blaze" {
+		t.Errorf("Officers = %+v", team.Officers)
+	}
+	if team.MOTD == nil || team.MOTD.MOTD != `Season push, 50 races a day, "no excuses"` {
+		t.Errorf("MOTD = %+v", team.MOTD)
+	}
+	if len(team.Stats) != 2 || team.Stats[0].Board != "daily" || team.Stats[0].Speed() != 120 {
+		t.Errorf("Stats = %+v", team.Stats)
+	}
+}
+
+func TestParseTeamPageMissing(t *testing.T) {
+	tests := map[string][]byte{
+		"null team": readTestdata(t, "team_page_missing.html"),
+		"no router": []byte("<!DOCTYPE html>\n<html><body><div id=\"root\"></div></body></html>\n"),
+	}
+	for name, page := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseTeamPage(page); !errors.Is(err, ErrTeamNotFound) {
+				t.Errorf("parseTeamPage() error = %v, want ErrTeamNotFound", err)
+			}
+		})
+	}
+}
+
+func TestParseScoreboardPage(t *testing.T) {
+	board, err := parseScoreboardPage(readTestdata(t, "scoreboard_individual_daily.html"), ScoreboardIndividual, ScoreboardDaily)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(board.Rows) != 2 {
+		t.Fatalf("Rows = %+v", board.Rows)
+	}
+	first := board.Rows[0]
+	if first.Rank != 1 || first.Races != 300 || first.Points != 1250000 || first.Speed != 112 || first.Team != nil {
+		t.Errorf("Rows[0] = %+v", first)
+	}
+	if first.User == nil || first.User.Username != "speedy" || first.User.Tag == nil || *first.User.Tag != "FAST" {
+		t.Errorf("Rows[0].User = %+v", first.User)
+	}
+	// Rows without time typed have no speed rather than dividing by zero
+	second := board.Rows[1]
+	if second.Speed != 0 || second.User == nil || second.User.Tag != nil {
+		t.Errorf("Rows[1] = %+v", second)
+	}
+
+	board, err = parseScoreboardPage(readTestdata(t, "scoreboard_team_season.html"), ScoreboardTeam, ScoreboardSeason)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(board.Rows) != 1 || board.Rows[0].User != nil || board.Rows[0].Team == nil {
+		t.Fatalf("Rows = %+v", board.Rows)
+	}
+	if team := board.Rows[0].Team; team.TeamID != 7 || team.Tag != "FAST" || team.Name != "Fast Fingers" || board.Rows[0].Speed != 64 {
+		t.Errorf("Rows[0] = %+v, Team = %+v", board.Rows[0], team)
+	}
+}
+
+func TestParseScoreboardPageErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		page      []byte
+		kind      ScoreboardKind
+		timeframe ScoreboardTimeframe
+	}{
+		{
+			name:      "other board",
+			page:      readTestdata(t, "scoreboard_individual_daily.html"),
+			kind:      ScoreboardIndividual,
+			timeframe: ScoreboardWeekly,
+		},
+		{
+			name:      "missing info",
+			page:      readTestdata(t, "team_page_missing.html"),
+			kind:      ScoreboardIndividual,
+			timeframe: ScoreboardDaily,
+		},
+		{
+			name:      "invalid json",
+			page:      []byte("window.NTROUTER = {\nSCOREBOARD_INFO: {\"kind\":,\n};"),
+			kind:      ScoreboardIndividual,
+			timeframe: ScoreboardDaily,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := parseScoreboardPage(test.page, test.kind, test.timeframe); !errors.Is(err, ErrScoreboardParse) {
+				t.Errorf("parseScoreboardPage() error = %v, want ErrScoreboardParse", err)
+			}
+		})
+	}
+}
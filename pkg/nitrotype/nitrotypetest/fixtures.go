@@ -0,0 +1,169 @@
+package nitrotypetest
+
+import (
+	"encoding/json"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+)
+
+// globalsFixture is a trimmed down NTGLOBALS containing a few entries of each section.
+const globalsFixture = `{
+	"ACTIVE_SEASONS": [
+		{"seasonID": 31, "name": "Neon Nights", "startStamp": 1640995200, "endStamp": 1648771200, "className": "neon", "achievementGroupID": 12, "achievementGroupName": "Neon Nights", "totalRewards": 40}
+	],
+	"ACHIEVEMENTS": {
+		"LIST": [
+			{"achievementID": 1, "gid": 1, "ruleGroup": "races", "name": "Rookie", "points": 10, "rules": [{"field": "racesPlayed", "comparison": ">=", "value": 100}], "reward": {"type": "money", "value": 10000}, "rewardDesc": "$10,000", "hidden": 0, "active": 1, "newCarThumbs": null, "seasonID": null, "startStamp": null, "endStamp": null, "seasonName": null, "seasonClassName": null},
+			{"achievementID": 2, "gid": 1, "ruleGroup": "speed", "name": "Speed Demon", "points": 25, "rules": [{"field": "avgSpeed", "comparison": ">=", "value": 100}], "reward": {"type": "car", "value": 9}, "rewardDesc": "Wind Wing", "hidden": 0, "active": 1, "newCarThumbs": ["9_small_1.png"], "seasonID": null, "startStamp": null, "endStamp": null, "seasonName": null, "seasonClassName": null}
+		],
+		"GROUP": [
+			{"achievementGroupID": 1, "site": "nitrotype", "name": "Career", "type": "career", "seasonID": 0, "img": null, "displayOrder": 1, "id": 1, "order": 1, "seasonClassName": null, "startStamp": null, "endStamp": null, "seasonName": null}
+		],
+		"TEXT": {
+			"racesPlayed": {"text": "Races Played"},
+			"avgSpeed": {"text": "Average Speed", "format": "wpm"}
+		}
+	},
+	"CARS": [
+		{"id": 1, "carID": 1, "name": "Lamborgotti Mephisto SS", "longDescription": "The starter car.", "options": {"rarity": "common", "largeSrc": "1_large_1.png", "smallSrc": "1_small_1.png"}, "enterSound": "default", "price": 0, "lastModified": 1577836800},
+		{"id": 9, "carID": 9, "name": "Wind Wing", "longDescription": "Floats on air.", "options": {"rarity": "rare", "largeSrc": "9_large_1.png", "smallSrc": "9_small_1.png"}, "enterSound": "default", "price": 200000, "lastModified": 1577836800},
+		{"id": 177, "assetKey": "dino", "carID": 177, "name": "The Dino", "longDescription": "Roar.", "options": {"rarity": "legendary", "largeSrc": "177_large_1.png", "smallSrc": "177_small_1.png", "isAnimated": 1}, "enterSound": "roar", "price": 5000000, "lastModified": 1609459200}
+	],
+	"PRODUCTS": {
+		"gold": {"productID": 1, "SKU": "GOLD_LIFETIME", "assetKey": null, "type": "gold", "name": "Gold Membership", "featured": 1, "description": "Lifetime gold.", "cashReward": 0, "price": "9.99", "salePrice": "9.99", "saleEnds": 0, "active": 1}
+	},
+	"GLOBAL_ALERT": false,
+	"LOOT": [
+		{"lootID": 100, "type": "trail", "name": "Sparkle Trail", "options": {"src": "sparkle", "type": "trail", "rarity": "rare"}, "lastModified": 1577836800},
+		{"lootID": 200, "type": "title", "name": "Speedster", "options": {"rarity": "common"}, "longDescription": "Go fast.", "price": 5000, "lastModified": 1577836800}
+	],
+	"SHOP": [
+		{"category": "daily", "startStamp": 1640995200, "expiration": 1641081600, "items": [{"type": "car", "id": 9, "price": 200000, "shortDescription": null, "longDescription": null, "slrID": 1}], "shopReleaseID": 501},
+		{"category": "featured", "startStamp": 1640995200, "expiration": 1641600000, "items": [{"type": "loot", "id": 100, "price": 50000, "shortDescription": null, "longDescription": null, "slrID": 2}], "shopReleaseID": 502}
+	],
+	"DEALERSHIP": [
+		{"dealershipID": 1, "assetKey": "classic", "name": "Classic Dealership", "expiration": null, "items": [{"type": "car", "id": 1, "price": 0, "shortDescription": null, "longDescription": null, "dlID": 1}]}
+	],
+	"CHALLENGES": [
+		{"challengeID": 1, "duration": "daily", "type": "races", "reward": 5000, "goal": 10, "expiration": 1641081600}
+	],
+	"STARTING_CARS": [1],
+	"FRIEND_LIMITS": {"basic": 100, "gold": 200},
+	"PAGE_LABELS": {"garage": "Garage"},
+	"ONE_WAY_FRIEND_IDS": [],
+	"TEAM_INFO": {"price": 500000, "minRaces": 100, "maxMembers": 50, "maxOfficers": 5, "motdUpdateInterval": 86400, "autoRemoveOptions": [7, 14, 30]},
+	"SEASON_LEVELS": {"startingLevels": 10, "experiencePerStartingLevel": 5000, "experiencePerAchievementLevel": 10000, "experiencePerExtraLevels": 20000, "extraLevelReward": 25000},
+	"TEACHERS_URL": "https://www.nitrotype.com/teachers",
+	"SITES": {"nitrotype": "https://www.nitrotype.com"},
+	"CAR_URL": "/cars/",
+	"CAR_PAINTED_URL": "/cars/painted/",
+	"CASH_SENDING": {"minLevel": 20, "minimum": 1000, "maximum": 10000000, "maxPerWeek": 10000000, "maxPerWeekTeams": 20000000, "feePercent": 0.05, "minAccountAge": 30},
+	"SCOREBOARD_RANK_MINIMUMS": {
+		"individual": {"season": 100, "monthly": 50, "weekly": 25, "daily": 10},
+		"team": {"season": 1000, "monthly": 500, "weekly": 250, "daily": 100}
+	},
+	"LOOT_CONFIG": {"trail": {"defaults": [], "maxEquipped": 1, "name": "Trails"}},
+	"CHALLENGE_TYPES": {"races": ["daily", "weekly"]}
+}`
+
+// racersFixture contains racer profiles in the same shape as RACER_INFO.
+const racersFixture = `[
+	{"userID": 12, "username": "speedy", "membership": "gold", "displayName": "Speedy", "title": "Speedster", "experience": 1250000, "level": 120, "teamID": 7, "lookingForTeam": 0, "carID": 9, "carHueAngle": 60, "totalCars": 3, "nitros": 40, "nitrosUsed": 1200, "racesPlayed": 25000, "longestSession": 400, "avgSpeed": 112, "highestSpeed": 150, "allowFriendRequests": 1, "profileViews": 900, "createdStamp": 1500000000, "tag": "FAST", "tagColor": "ff0000", "garage": ["1", "9", "177"], "cars": [[1, "owned", 0, 1500000000], [9, "owned", 60, 1550000000], [177, "owned", 0, 1610000000]], "loot": [{"lootID": 100, "type": "trail", "name": "Sparkle Trail", "assetKey": "sparkle", "options": {"src": "sparkle", "type": "trail", "rarity": "rare"}, "equipped": 1, "createdStamp": 1600000000}]},
+	{"userID": 55, "username": "turtle", "membership": "basic", "displayName": "Turtle", "title": "Rookie", "experience": 15000, "level": 8, "teamID": null, "lookingForTeam": 1, "carID": 1, "carHueAngle": 0, "totalCars": 1, "nitros": 2, "nitrosUsed": 3, "racesPlayed": 60, "longestSession": 12, "avgSpeed": 35, "highestSpeed": 48, "allowFriendRequests": 1, "profileViews": 4, "createdStamp": 1630000000, "tag": null, "tagColor": null, "garage": [1], "cars": [[1, "owned", 0, 1630000000]], "loot": []}
+]`
+
+// teamsFixture contains team pages in the same shape as TEAM_INFO.
+const teamsFixture = `[
+	{
+		"info": {"teamID": 7, "userID": 12, "tag": "FAST", "tagColor": "ff0000", "name": "Fast Fingers", "enrollment": "open", "minLevel": 10, "minRaces": 100, "minSpeed": 60, "autoRemove": 30, "otherRequirements": "Race daily!", "members": 3, "profileViews": 5000, "lastActivity": 1641000000, "createdStamp": 1500000000},
+		"members": [
+			{"userID": 12, "username": "speedy", "displayName": "Speedy", "membership": "gold", "title": "Speedster", "level": 120, "carID": 9, "carHueAngle": 60, "role": "captain", "teamJoined": 1500000000, "lastLogin": 1641000000, "played": 900, "typed": 2520000, "errs": 4000, "secs": 270000},
+			{"userID": 77, "username": "blaze", "displayName": "Blaze", "membership": "basic", "title": "Racer", "level": 64, "carID": 1, "carHueAngle": 0, "role": "officer", "teamJoined": 1550000000, "lastLogin": 1640900000, "played": 400, "typed": 1000000, "errs": 3000, "secs": 150000},
+			{"userID": 88, "username": "slowpoke", "displayName": "Slowpoke", "membership": "basic", "title": "Rookie", "level": 15, "carID": 1, "carHueAngle": 0, "role": "member", "teamJoined": 1600000000, "lastLogin": 1630000000, "played": 50, "typed": 60000, "errs": 900, "secs": 24000}
+		],
+		"motd": {"motd": "Welcome to FAST, race at least 10 a day.", "username": "speedy", "lastModified": 1640000000},
+		"stats": [
+			{"board": "daily", "played": 120, "typed": 300000, "errs": 800, "secs": 32000, "stamp": 1640995200},
+			{"board": "weekly", "played": 700, "typed": 1800000, "errs": 4200, "secs": 190000, "stamp": 1640563200},
+			{"board": "monthly", "played": 2600, "typed": 6500000, "errs": 15000, "secs": 700000, "stamp": 1638316800},
+			{"board": "season", "played": 5000, "typed": 12600000, "errs": 30000, "secs": 1350000, "stamp": 1640995200}
+		]
+	}
+]`
+
+// scoreboardRowsFixture contains the rows of every individual and team board.
+const scoreboardRowsFixture = `{
+	"individual": [
+		{"rank": 1, "user": {"userID": 12, "username": "speedy", "displayName": "Speedy", "tag": "FAST", "tagColor": "ff0000"}, "races": 300, "speed": 112, "points": 168000},
+		{"rank": 2, "user": {"userID": 77, "username": "blaze", "displayName": "Blaze", "tag": "FAST", "tagColor": "ff0000"}, "races": 150, "speed": 80, "points": 60000},
+		{"rank": 3, "user": {"userID": 55, "username": "turtle", "displayName": "Turtle", "tag": null, "tagColor": null}, "races": 40, "speed": 35, "points": 7000}
+	],
+	"team": [
+		{"rank": 1, "team": {"teamID": 7, "tag": "FAST", "tagColor": "ff0000", "name": "Fast Fingers"}, "races": 1200, "speed": 94, "points": 564000},
+		{"rank": 2, "team": {"teamID": 9, "tag": "SLOW", "tagColor": "00ff00", "name": "Slow and Steady"}, "races": 600, "speed": 50, "points": 150000}
+	]
+}`
+
+// DefaultGlobals returns the NTGLOBALS served by a new Server, without TOP_PLAYERS and TOP_TEAMS.
+func DefaultGlobals() nitrotype.NTGlobalsLegacy {
+	var output nitrotype.NTGlobalsLegacy
+	if err := json.Unmarshal([]byte(globalsFixture), &output); err != nil {
+		panic("nitrotypetest: invalid globals fixture: " + err.Error())
+	}
+	return output
+}
+
+// DefaultTopPlayers returns the ordered Top Players served by a new Server.
+func DefaultTopPlayers() []nitrotype.RankItem {
+	return []nitrotype.RankItem{
+		{Index: 1, ID: 12, Position: 1},
+		{Index: 2, ID: 55, Position: 2},
+	}
+}
+
+// DefaultTopTeams returns the ordered Top Teams served by a new Server.
+func DefaultTopTeams() []nitrotype.RankItem {
+	return []nitrotype.RankItem{
+		{Index: 1, ID: 7, Position: 1},
+	}
+}
+
+// DefaultRacers returns the racer profiles served by a new Server.
+func DefaultRacers() []nitrotype.NTPlayer {
+	var output []nitrotype.NTPlayer
+	if err := json.Unmarshal([]byte(racersFixture), &output); err != nil {
+		panic("nitrotypetest: invalid racers fixture: " + err.Error())
+	}
+	return output
+}
+
+// DefaultTeams returns the team pages served by a new Server.
+func DefaultTeams() []nitrotype.NTTeam {
+	var output []nitrotype.NTTeam
+	if err := json.Unmarshal([]byte(teamsFixture), &output); err != nil {
+		panic("nitrotypetest: invalid teams fixture: " + err.Error())
+	}
+	return output
+}
+
+// DefaultScoreboards returns every board served by a new Server, longer timeframes have more races.
+func DefaultScoreboards() []nitrotype.NTScoreboard {
+	var rows map[nitrotype.ScoreboardKind][]nitrotype.NTScoreboardRow
+	if err := json.Unmarshal([]byte(scoreboardRowsFixture), &rows); err != nil {
+		panic("nitrotypetest: invalid scoreboard fixture: " + err.Error())
+	}
+
+	var output []nitrotype.NTScoreboard
+	for _, kind := range nitrotype.ScoreboardKinds {
+		for i, timeframe := range nitrotype.ScoreboardTimeframes {
+			scale := []int{1, 7, 30, 90}[i]
+			board := nitrotype.NTScoreboard{Kind: kind, Timeframe: timeframe}
+			for _, row := range rows[kind] {
+				row.Races *= scale
+				row.Points *= int64(scale)
+				board.Rows = append(board.Rows, row)
+			}
+			output = append(output, board)
+		}
+	}
+	return output
+}
@@ -0,0 +1,376 @@
+// Package nitrotypetest provides a fake Nitro Type site for exercising the scraper without network access.
+package nitrotypetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi"
+)
+
+// BootstrapPath is where the fake homepage links bootstrap.js from.
+const BootstrapPath = "/dist/site/js/bootstrap.js"
+
+// Server is a local Nitro Type site serving a homepage, bootstrap.js, racer profiles, team pages and scoreboards.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.RWMutex
+	globals    nitrotype.NTGlobalsLegacy
+	topPlayers []nitrotype.RankItem
+	topTeams   []nitrotype.RankItem
+	racers     map[string]nitrotype.NTPlayer
+	teams      map[string]nitrotype.NTTeam
+	boards     map[string]nitrotype.NTScoreboard
+	outage     nitrotype.PageKind
+}
+
+// NewServer starts a fake site populated with the default fixtures.
+// The caller should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		globals:    DefaultGlobals(),
+		topPlayers: DefaultTopPlayers(),
+		topTeams:   DefaultTopTeams(),
+		racers:     map[string]nitrotype.NTPlayer{},
+		teams:      map[string]nitrotype.NTTeam{},
+		boards:     map[string]nitrotype.NTScoreboard{},
+	}
+	for _, racer := range DefaultRacers() {
+		s.racers[strings.ToLower(racer.Username)] = racer
+	}
+	for _, team := range DefaultTeams() {
+		s.teams[strings.ToUpper(team.Info.Tag)] = team
+	}
+	for _, board := range DefaultScoreboards() {
+		s.boards[string(board.Kind)+"/"+string(board.Timeframe)] = board
+	}
+
+	r := chi.NewRouter()
+	r.Use(s.outageMiddleware)
+	r.Get("/", s.handleHomepage)
+	r.Get(BootstrapPath, s.handleBootstrap)
+	r.Get("/racer/{username}", s.handleRacer)
+	r.Get("/team/{tag}", s.handleTeam)
+	r.Get("/scoreboard/{kind}/{timeframe}", s.handleScoreboard)
+
+	s.Server = httptest.NewServer(r)
+	return s
+}
+
+// Fetcher returns a HTTPFetcher pointed at this server.
+func (s *Server) Fetcher() nitrotype.HTTPFetcher {
+	return nitrotype.HTTPFetcher{
+		Client:  s.Client(),
+		BaseURL: s.URL,
+	}
+}
+
+// SetGlobals replaces the NTGLOBALS entries served in bootstrap.js.
+func (s *Server) SetGlobals(globals nitrotype.NTGlobalsLegacy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.globals = globals
+}
+
+// SetTopPlayers replaces the ordered TOP_PLAYERS entry served in bootstrap.js.
+func (s *Server) SetTopPlayers(players []nitrotype.RankItem, teams []nitrotype.RankItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.topPlayers = players
+	s.topTeams = teams
+}
+
+// AddRacer adds or replaces a racer profile.
+func (s *Server) AddRacer(racer nitrotype.NTPlayer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.racers[strings.ToLower(racer.Username)] = racer
+}
+
+// RemoveRacer removes a racer profile so it is reported as missing.
+func (s *Server) RemoveRacer(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.racers, strings.ToLower(username))
+}
+
+// AddTeam adds or replaces a team page.
+func (s *Server) AddTeam(team nitrotype.NTTeam) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teams[strings.ToUpper(team.Info.Tag)] = team
+}
+
+// RemoveTeam removes a team page so it is reported as missing.
+func (s *Server) RemoveTeam(tag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.teams, strings.ToUpper(tag))
+}
+
+// SetScoreboard replaces the rows of a scoreboard.
+func (s *Server) SetScoreboard(board nitrotype.NTScoreboard) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.boards[string(board.Kind)+"/"+string(board.Timeframe)] = board
+}
+
+// SetUnavailable makes every page a challenge, maintenance or error page until reset with an empty kind.
+func (s *Server) SetUnavailable(kind nitrotype.PageKind) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outage = kind
+}
+
+// outageMiddleware serves the page configured with SetUnavailable instead of the real content.
+func (s *Server) outageMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		outage := s.outage
+		s.mu.RUnlock()
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch outage {
+		case nitrotype.PageChallenge:
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("<!DOCTYPE html>\n<html><head><title>Just a moment...</title></head><body><form id=\"challenge-form\" action=\"/?__cf_chl_f_tk=abc\"></form></body></html>\n"))
+		case nitrotype.PageMaintenance:
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("<!DOCTYPE html>\n<html><head><title>Maintenance</title></head><body>Nitro Type is down for maintenance.</body></html>\n"))
+		case nitrotype.PageError:
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<!DOCTYPE html>\n<html><head><title>nitrotype.com | 502: Bad gateway</title></head><body><div id=\"cf-error-details\"></div></body></html>\n"))
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func (s *Server) handleHomepage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Nitro Type | Competitive Typing Game</title></head>
+<body>
+<div id="root"></div>
+<script src="%s"></script>
+</body>
+</html>
+`, html.EscapeString(BootstrapPath))
+}
+
+func (s *Server) handleBootstrap(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.globals))
+	for key := range s.globals {
+		if key == "TOP_PLAYERS" || key == "TOP_TEAMS" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("window.NTGLOBALS=window.NTGLOBALS||{};[")
+	b.WriteString(`["TOP_PLAYERS",{"users":`)
+	writeRankMap(&b, s.topPlayers)
+	b.WriteString(`,"teams":`)
+	writeRankMap(&b, s.topTeams)
+	b.WriteString(`}]`)
+	for _, key := range keys {
+		value, err := json.Marshal(s.globals[key])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(&b, `,[%q,%s]`, key, value)
+	}
+	b.WriteString("].forEach(function(e){window.NTGLOBALS[e[0]]=e[1]});\n")
+
+	w.Header().Set("Content-Type", "application/javascript")
+	w.Write([]byte(b.String()))
+}
+
+func (s *Server) handleRacer(w http.ResponseWriter, r *http.Request) {
+	username := strings.ToLower(chi.URLParam(r, "username"))
+
+	s.mu.RLock()
+	racer, ok := s.racers[username]
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<!DOCTYPE html>\n<html><body><div id=\"root\"></div></body></html>\n"))
+		return
+	}
+
+	info, err := racerInfoJSON(racer)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<body>
+<div id="root"></div>
+<script>
+window.NTROUTER = {
+RACER_INFO: %s,
+LOGGED_IN: false
+};
+</script>
+</body>
+</html>
+`, info)
+}
+
+func (s *Server) handleTeam(w http.ResponseWriter, r *http.Request) {
+	tag := strings.ToUpper(chi.URLParam(r, "tag"))
+
+	s.mu.RLock()
+	team, ok := s.teams[tag]
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<!DOCTYPE html>\n<html><body><div id=\"root\"></div></body></html>\n"))
+		return
+	}
+
+	// The site does not send the derived officers list
+	info, err := json.Marshal(map[string]interface{}{
+		"info":    team.Info,
+		"members": team.Members,
+		"motd":    team.MOTD,
+		"stats":   team.Stats,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<body>
+<div id="root"></div>
+<script>
+window.NTROUTER = {
+TEAM_INFO: %s,
+LOGGED_IN: false
+};
+</script>
+</body>
+</html>
+`, info)
+}
+
+func (s *Server) handleScoreboard(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "kind") + "/" + chi.URLParam(r, "timeframe")
+
+	s.mu.RLock()
+	board, ok := s.boards[key]
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<!DOCTYPE html>\n<html><body><div id=\"root\"></div></body></html>\n"))
+		return
+	}
+
+	info, err := json.Marshal(scoreboardInfo(board))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<body>
+<div id="root"></div>
+<script>
+window.NTROUTER = {
+SCOREBOARD_INFO: %s,
+LOGGED_IN: false
+};
+</script>
+</body>
+</html>
+`, info)
+}
+
+// writeRankMap writes rank items as a JSON object keeping their order.
+func writeRankMap(b *strings.Builder, items []nitrotype.RankItem) {
+	b.WriteString("{")
+	for i, item := range items {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(b, `"%d":%d`, item.ID, item.Position)
+	}
+	b.WriteString("}")
+}
+
+// racerInfoJSON encodes a racer the way the site does, with cars as tuples.
+func racerInfoJSON(racer nitrotype.NTPlayer) ([]byte, error) {
+	data, err := json.Marshal(racer)
+	if err != nil {
+		return nil, err
+	}
+	var output map[string]interface{}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+	cars := make([][]interface{}, 0, len(racer.Cars))
+	for _, car := range racer.Cars {
+		cars = append(cars, []interface{}{car.CarID, car.Status, car.CarHueAngle, car.CreatedStamp})
+	}
+	output["cars"] = cars
+	return json.Marshal(output)
+}
+
+// scoreboardInfo encodes a scoreboard the way the site does, with time and characters typed instead of speed.
+func scoreboardInfo(board nitrotype.NTScoreboard) map[string]interface{} {
+	scores := make([]map[string]interface{}, 0, len(board.Rows))
+	for _, row := range board.Rows {
+		secs := int64(row.Races) * 30
+		score := map[string]interface{}{
+			"rank":   row.Rank,
+			"played": row.Races,
+			"typed":  int64(math.Round(row.Speed * 5 * float64(secs) / 60)),
+			"secs":   secs,
+			"points": row.Points,
+		}
+		if row.User != nil {
+			score["userID"] = row.User.UserID
+			score["username"] = row.User.Username
+			score["displayName"] = row.User.DisplayName
+			score["tag"] = row.User.Tag
+			score["tagColor"] = row.User.TagColor
+		}
+		if row.Team != nil {
+			score["teamID"] = row.Team.TeamID
+			score["tag"] = row.Team.Tag
+			score["tagColor"] = row.Team.TagColor
+			score["name"] = row.Team.Name
+		}
+		scores = append(scores, score)
+	}
+	return map[string]interface{}{
+		"kind":      board.Kind,
+		"timeframe": board.Timeframe,
+		"scores":    scores,
+	}
+}
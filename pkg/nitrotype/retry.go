@@ -0,0 +1,216 @@
+package nitrotype
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// DefaultRetryPolicy makes up to three attempts, waiting around 2s then 4s between them.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   2 * time.Second,
+	MaxDelay:    30 * time.Second,
+	Jitter:      0.5,
+}
+
+// RetryPolicy decides how often and how long to wait before repeating a failed scrape.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first, values below 1 mean a single attempt.
+	MaxAttempts int
+
+	// BaseDelay is the wait after the first failure, it doubles after every following failure.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the wait between attempts, zero means no cap.
+	MaxDelay time.Duration
+
+	// Jitter is the fraction (0 to 1) of each wait that is randomised so scrapes don't retry in lockstep.
+	Jitter float64
+
+	// Retryable reports whether a failed attempt is worth repeating, defaults to IsTransient.
+	Retryable func(err error) bool
+
+	// OnAttempt is called after every attempt, successful or not.
+	OnAttempt func(attempt RetryAttempt)
+}
+
+// RetryAttempt describes the outcome of a single attempt.
+type RetryAttempt struct {
+	// Op names what was being fetched, such as "bootstrap", "player/{username}" or "team/{tag}".
+	Op string
+
+	// Attempt counts from 1.
+	Attempt int
+
+	// Err is the failure of this attempt, nil when it succeeded.
+	Err error
+
+	// Delay is how long until the next attempt, only set when Retrying.
+	Delay time.Duration
+
+	// Retrying is whether another attempt will be made.
+	Retrying bool
+}
+
+// RetryError is returned when more than one attempt was made and none succeeded.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("gave up after %d attempts: %s", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// Backoff returns the wait before the attempt following the given failed attempt, before jitter.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 || attempt < 1 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// Do runs fn until it succeeds, fails with an error that is not retryable, runs out of attempts or ctx is done.
+func (p RetryPolicy) Do(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsTransient
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		retrying := err != nil && attempt < maxAttempts && ctx.Err() == nil && retryable(err)
+
+		var delay time.Duration
+		if retrying {
+			delay = p.jitter(p.Backoff(attempt))
+		}
+		if p.OnAttempt != nil {
+			p.OnAttempt(RetryAttempt{
+				Op:       op,
+				Attempt:  attempt,
+				Err:      err,
+				Delay:    delay,
+				Retrying: retrying,
+			})
+		}
+
+		if !retrying {
+			return retryError(attempt, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return retryError(attempt, err)
+		}
+	}
+}
+
+// retryError wraps the last failure once more than one attempt has been made.
+func retryError(attempts int, err error) error {
+	if err == nil || attempts < 2 {
+		return err
+	}
+	return &RetryError{Attempts: attempts, Err: err}
+}
+
+// jitter shortens delay by a random amount up to the Jitter fraction.
+func (p RetryPolicy) jitter(delay time.Duration) time.Duration {
+	if p.Jitter <= 0 || delay <= 0 {
+		return delay
+	}
+	jitter := p.Jitter
+	if jitter > 1 {
+		jitter = 1
+	}
+	return delay - time.Duration(rand.Float64()*jitter*float64(delay))
+}
+
+// RetryFetcher repeats failed fetches of the underlying Fetcher according to a RetryPolicy.
+type RetryFetcher struct {
+	next   Fetcher
+	policy RetryPolicy
+}
+
+// NewRetryFetcher wraps a Fetcher with the given retry policy.
+func NewRetryFetcher(next Fetcher, policy RetryPolicy) *RetryFetcher {
+	return &RetryFetcher{
+		next:   next,
+		policy: policy,
+	}
+}
+
+func (f *RetryFetcher) GetBootstrapData(ctx context.Context) (*NTGlobalsLegacy, error) {
+	var output *NTGlobalsLegacy
+	err := f.policy.Do(ctx, "bootstrap", func(ctx context.Context) error {
+		source, err := f.next.GetBootstrapData(ctx)
+		output = source
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (f *RetryFetcher) GetPlayerData(ctx context.Context, username string) (*NTPlayer, error) {
+	var output *NTPlayer
+	err := f.policy.Do(ctx, "player/"+username, func(ctx context.Context) error {
+		racer, err := f.next.GetPlayerData(ctx, username)
+		output = racer
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (f *RetryFetcher) GetTeamData(ctx context.Context, tag string) (*NTTeam, error) {
+	var output *NTTeam
+	err := f.policy.Do(ctx, "team/"+tag, func(ctx context.Context) error {
+		team, err := f.next.GetTeamData(ctx, tag)
+		output = team
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (f *RetryFetcher) GetScoreboard(ctx context.Context, kind ScoreboardKind, timeframe ScoreboardTimeframe) (*NTScoreboard, error) {
+	var output *NTScoreboard
+	err := f.policy.Do(ctx, "scoreboard/"+string(kind)+"/"+string(timeframe), func(ctx context.Context) error {
+		scoreboard, err := f.next.GetScoreboard(ctx, kind, timeframe)
+		output = scoreboard
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
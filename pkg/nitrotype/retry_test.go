@@ -0,0 +1,152 @@
+package nitrotype_test
+
+import (
+	"context"
+	"errors"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := nitrotype.RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: 0},
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 5 * time.Second},
+		{attempt: 100, want: 5 * time.Second},
+	}
+	for _, test := range tests {
+		if got := policy.Backoff(test.attempt); got != test.want {
+			t.Errorf("Backoff(%d) = %s, want %s", test.attempt, got, test.want)
+		}
+	}
+
+	uncapped := nitrotype.RetryPolicy{BaseDelay: time.Second}
+	if got := uncapped.Backoff(6); got != 32*time.Second {
+		t.Errorf("Backoff(6) without MaxDelay = %s, want 32s", got)
+	}
+	if got := (nitrotype.RetryPolicy{MaxDelay: time.Second}).Backoff(3); got != 0 {
+		t.Errorf("Backoff(3) without BaseDelay = %s, want 0", got)
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		errs        []error
+		attempts    int
+		wantErr     error
+	}{
+		{name: "success", maxAttempts: 3, errs: []error{nil}, attempts: 1},
+		{name: "transient then success", maxAttempts: 3, errs: []error{nitrotype.ErrTimeout, nil}, attempts: 2},
+		{name: "gives up at max attempts", maxAttempts: 3, errs: []error{nitrotype.ErrTimeout, nitrotype.ErrNetwork, nitrotype.ErrMaintenance, nil}, attempts: 3, wantErr: nitrotype.ErrMaintenance},
+		{name: "single attempt", maxAttempts: 0, errs: []error{nitrotype.ErrTimeout, nil}, attempts: 1, wantErr: nitrotype.ErrTimeout},
+		{name: "not transient", maxAttempts: 3, errs: []error{nitrotype.ErrPlayerNotFound, nil}, attempts: 1, wantErr: nitrotype.ErrPlayerNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var reported []nitrotype.RetryAttempt
+			policy := nitrotype.RetryPolicy{
+				MaxAttempts: test.maxAttempts,
+				BaseDelay:   time.Millisecond,
+				OnAttempt:   func(attempt nitrotype.RetryAttempt) { reported = append(reported, attempt) },
+			}
+			calls := 0
+			err := policy.Do(context.Background(), "test", func(ctx context.Context) error {
+				calls++
+				return test.errs[calls-1]
+			})
+
+			if calls != test.attempts || len(reported) != test.attempts {
+				t.Errorf("calls = %d, reported = %d, want %d", calls, len(reported), test.attempts)
+			}
+			if !errors.Is(err, test.wantErr) || (test.wantErr == nil) != (err == nil) {
+				t.Errorf("Do() error = %v, want %v", err, test.wantErr)
+			}
+			var retryErr *nitrotype.RetryError
+			if isRetryErr := errors.As(err, &retryErr); isRetryErr != (err != nil && test.attempts > 1) {
+				t.Errorf("Do() error = %v, want a RetryError only after more than one attempt", err)
+			}
+			if last := reported[len(reported)-1]; last.Retrying || last.Attempt != test.attempts {
+				t.Errorf("last attempt = %+v", last)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyJitter(t *testing.T) {
+	for _, jitter := range []float64{0.5, 2} {
+		var delays []time.Duration
+		policy := nitrotype.RetryPolicy{
+			MaxAttempts: 6,
+			BaseDelay:   100 * time.Microsecond,
+			MaxDelay:    time.Millisecond,
+			Jitter:      jitter,
+			OnAttempt: func(attempt nitrotype.RetryAttempt) {
+				if attempt.Retrying {
+					delays = append(delays, attempt.Delay)
+				}
+			},
+		}
+		policy.Do(context.Background(), "test", func(ctx context.Context) error {
+			return nitrotype.ErrTimeout
+		})
+
+		if len(delays) != 5 {
+			t.Fatalf("jitter %v: delays = %v, want 5", jitter, delays)
+		}
+		// Jitter above 1 is treated as 1, the wait may shrink to nothing but never grow
+		low := 1 - jitter
+		if low < 0 {
+			low = 0
+		}
+		for i, delay := range delays {
+			backoff := policy.Backoff(i + 1)
+			if delay > backoff || float64(delay) < low*float64(backoff) {
+				t.Errorf("jitter %v: delay %d = %s, want between %s and %s", jitter, i+1, delay, time.Duration(low*float64(backoff)), backoff)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyCanceledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	policy := nitrotype.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Hour,
+		OnAttempt: func(attempt nitrotype.RetryAttempt) {
+			if attempt.Retrying {
+				cancel()
+			}
+		},
+	}
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- policy.Do(ctx, "test", func(ctx context.Context) error {
+			calls++
+			return nitrotype.ErrTimeout
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, nitrotype.ErrTimeout) {
+			t.Errorf("Do() error = %v, want the last failure", err)
+		}
+		if calls != 1 {
+			t.Errorf("calls = %d, want no attempt after cancel", calls)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Do() kept waiting after ctx was canceled")
+	}
+}
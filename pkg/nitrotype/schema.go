@@ -0,0 +1,223 @@
+package nitrotype
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// SchemaReport lists where the raw NTGLOBALS no longer matches the NTGlobals model.
+// Paths use "[]" for any array element and "*" for any map key.
+type SchemaReport struct {
+	UnknownKeys []string         `json:"unknownKeys"`
+	MissingKeys []string         `json:"missingKeys"`
+	Mismatches  []SchemaMismatch `json:"mismatches"`
+}
+
+// SchemaMismatch is a key whose value has a different JSON type than the model expects.
+type SchemaMismatch struct {
+	Path     string `json:"path"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// HasDrift reports whether any difference was found.
+func (r *SchemaReport) HasDrift() bool {
+	return len(r.UnknownKeys) > 0 || len(r.MissingKeys) > 0 || len(r.Mismatches) > 0
+}
+
+// CheckSchema compares the raw NTGLOBALS against the json tags of NTGlobals.
+func CheckSchema(raw NTGlobalsLegacy) (*SchemaReport, error) {
+	value, err := plainJSON(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &schemaChecker{
+		unknown:    map[string]bool{},
+		missing:    map[string]bool{},
+		mismatches: map[SchemaMismatch]bool{},
+	}
+	c.check("", value, reflect.TypeOf(NTGlobals{}))
+
+	output := &SchemaReport{
+		UnknownKeys: sortedKeys(c.unknown),
+		MissingKeys: sortedKeys(c.missing),
+		Mismatches:  make([]SchemaMismatch, 0, len(c.mismatches)),
+	}
+	for mismatch := range c.mismatches {
+		output.Mismatches = append(output.Mismatches, mismatch)
+	}
+	sort.Slice(output.Mismatches, func(i, j int) bool {
+		if output.Mismatches[i].Path != output.Mismatches[j].Path {
+			return output.Mismatches[i].Path < output.Mismatches[j].Path
+		}
+		return output.Mismatches[i].Actual < output.Mismatches[j].Actual
+	})
+	return output, nil
+}
+
+// schemaChecker collects differences, de-duplicated by path.
+type schemaChecker struct {
+	unknown    map[string]bool
+	missing    map[string]bool
+	mismatches map[SchemaMismatch]bool
+}
+
+func (c *schemaChecker) check(path string, value interface{}, t reflect.Type) {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) || t.Kind() == reflect.Interface {
+		// custom decoding accepts more than one shape
+		return
+	}
+
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Slice, reflect.Map:
+			nullable = true
+		}
+		if !nullable {
+			c.mismatch(path, schemaType(t), "null")
+		}
+		return
+	}
+
+	actual := jsonType(value)
+	expected := schemaType(t)
+	if expected != actual && !(expected == "integer" && actual == "number") {
+		c.mismatch(path, expected, actual)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if number := value.(float64); number != float64(int64(number)) {
+			c.mismatch(path, expected, "fractional number")
+		}
+	case reflect.Slice, reflect.Array:
+		for _, item := range value.([]interface{}) {
+			c.check(path+"[]", item, t.Elem())
+		}
+	case reflect.Map:
+		for _, item := range value.(map[string]interface{}) {
+			c.check(joinSchemaPath(path, "*"), item, t.Elem())
+		}
+	case reflect.Struct:
+		object := value.(map[string]interface{})
+		known := map[string]bool{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, optional := jsonFieldName(field)
+			if name == "" {
+				continue
+			}
+			known[name] = true
+
+			fieldPath := joinSchemaPath(path, name)
+			fieldValue, ok := object[name]
+			if !ok {
+				if !optional && field.Type.Kind() != reflect.Ptr {
+					c.missing[fieldPath] = true
+				}
+				continue
+			}
+			c.check(fieldPath, fieldValue, field.Type)
+		}
+		for key := range object {
+			if !known[key] {
+				c.unknown[joinSchemaPath(path, key)] = true
+			}
+		}
+	}
+}
+
+func (c *schemaChecker) mismatch(path string, expected string, actual string) {
+	c.mismatches[SchemaMismatch{Path: path, Expected: expected, Actual: actual}] = true
+}
+
+// jsonFieldName returns the JSON key of a struct field and whether it may be left out.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	optional := false
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			optional = true
+		}
+	}
+	return name, optional
+}
+
+// schemaType describes the JSON type a Go type is decoded from.
+func schemaType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return t.Kind().String()
+}
+
+// jsonType describes the type of a decoded JSON value.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func joinSchemaPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(set map[string]bool) []string {
+	output := make([]string, 0, len(set))
+	for key := range set {
+		output = append(output, key)
+	}
+	sort.Strings(output)
+	return output
+}
@@ -0,0 +1,85 @@
+package nitrotype_test
+
+import (
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"nt-bootstrap-scraper/pkg/nitrotype/nitrotypetest"
+	"reflect"
+	"testing"
+)
+
+func TestCheckSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(globals nitrotype.NTGlobalsLegacy)
+		want   nitrotype.SchemaReport
+	}{
+		{
+			name:   "unchanged",
+			change: func(globals nitrotype.NTGlobalsLegacy) {},
+		},
+		{
+			name: "added field",
+			change: func(globals nitrotype.NTGlobalsLegacy) {
+				globals["CASH_SENDING"].(map[string]interface{})["maxPerDay"] = 5000.0
+				globals["CARS"].([]interface{})[0].(map[string]interface{})["sparkle"] = true
+			},
+			want: nitrotype.SchemaReport{UnknownKeys: []string{"CARS[].sparkle", "CASH_SENDING.maxPerDay"}},
+		},
+		{
+			name: "added section",
+			change: func(globals nitrotype.NTGlobalsLegacy) {
+				globals["NEW_SECTION"] = map[string]interface{}{}
+			},
+			want: nitrotype.SchemaReport{UnknownKeys: []string{"NEW_SECTION"}},
+		},
+		{
+			name: "removed field",
+			change: func(globals nitrotype.NTGlobalsLegacy) {
+				delete(globals["CASH_SENDING"].(map[string]interface{}), "minLevel")
+			},
+			want: nitrotype.SchemaReport{MissingKeys: []string{"CASH_SENDING.minLevel"}},
+		},
+		{
+			name: "type change",
+			change: func(globals nitrotype.NTGlobalsLegacy) {
+				cash := globals["CASH_SENDING"].(map[string]interface{})
+				cash["minLevel"] = "20"
+				cash["minimum"] = 1000.5
+			},
+			want: nitrotype.SchemaReport{Mismatches: []nitrotype.SchemaMismatch{
+				{Path: "CASH_SENDING.minLevel", Expected: "integer", Actual: "string"},
+				{Path: "CASH_SENDING.minimum", Expected: "integer", Actual: "fractional number"},
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// The fake site adds the rank lists to its globals when serving them
+			globals := nitrotypetest.DefaultGlobals()
+			globals["TOP_PLAYERS"] = []interface{}{}
+			globals["TOP_TEAMS"] = []interface{}{}
+			test.change(globals)
+
+			report, err := nitrotype.CheckSchema(globals)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := test.want
+			if want.UnknownKeys == nil {
+				want.UnknownKeys = []string{}
+			}
+			if want.MissingKeys == nil {
+				want.MissingKeys = []string{}
+			}
+			if want.Mismatches == nil {
+				want.Mismatches = []nitrotype.SchemaMismatch{}
+			}
+			if !reflect.DeepEqual(*report, want) {
+				t.Errorf("CheckSchema() = %+v, want %+v", *report, want)
+			}
+			if report.HasDrift() != (test.name != "unchanged") {
+				t.Errorf("HasDrift() = %v", report.HasDrift())
+			}
+		})
+	}
+}
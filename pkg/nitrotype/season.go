@@ -0,0 +1,139 @@
+package nitrotype
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// SeasonProgress is where an amount of season XP puts a racer.
+type SeasonProgress struct {
+	Season                ActiveSeason  `json:"season"`
+	Experience            int64         `json:"experience"`
+	Level                 int           `json:"level"`
+	LevelExperience       int64         `json:"levelExperience"`
+	NextLevelExperience   int64         `json:"nextLevelExperience"`
+	ExperienceToNextLevel int64         `json:"experienceToNextLevel"`
+	RewardsEarned         int           `json:"rewardsEarned"`
+	ExtraLevels           int           `json:"extraLevels"`
+	ExtraLevelCash        int64         `json:"extraLevelCash"`
+	Target                *SeasonTarget `json:"target,omitempty"`
+}
+
+// SeasonTarget is how much XP is still needed to reach a level before the season ends.
+// ExperiencePerDay stays zero once the level is reached or the season has ended.
+type SeasonTarget struct {
+	Level               int     `json:"level"`
+	Experience          int64   `json:"experience"`
+	ExperienceRemaining int64   `json:"experienceRemaining"`
+	DaysRemaining       float64 `json:"daysRemaining"`
+	ExperiencePerDay    int64   `json:"experiencePerDay"`
+}
+
+// Season returns the season with the ID, or the season running at now when seasonID is zero.
+func (g *NTGlobals) Season(seasonID int, now time.Time) (*ActiveSeason, error) {
+	for i, season := range g.ActionSeasons {
+		if seasonID != 0 && season.SeasonID == seasonID {
+			return &g.ActionSeasons[i], nil
+		}
+		if seasonID == 0 && now.Unix() >= season.StartStamp && now.Unix() < season.EndStamp {
+			return &g.ActionSeasons[i], nil
+		}
+	}
+	if seasonID != 0 {
+		return nil, fmt.Errorf("%w: %d", ErrSeasonNotFound, seasonID)
+	}
+	return nil, fmt.Errorf("%w: no season is running", ErrSeasonNotFound)
+}
+
+// LevelExperience returns the XP needed to go from level-1 to level.
+// The first StartingLevels levels use the starting cost, levels up to the season's TotalRewards
+// unlock an achievement reward each, and levels past that are extra levels paying ExtraLevelReward cash.
+func (l SeasonLevels) LevelExperience(season ActiveSeason, level int) int64 {
+	switch {
+	case level <= 0:
+		return 0
+	case level <= l.StartingLevels:
+		return l.ExperiencePerStartingLevel
+	case level <= season.TotalRewards:
+		return l.ExperiencePerAchievementLevel
+	}
+	return l.ExperiencePerExtraLevels
+}
+
+// TotalExperience returns the season XP needed to reach level.
+func (l SeasonLevels) TotalExperience(season ActiveSeason, level int) int64 {
+	var total int64
+	last := l.lastRewardLevel(season)
+	for i := 1; i <= level && i <= last; i++ {
+		total += l.LevelExperience(season, i)
+	}
+	if level > last {
+		total += int64(level-last) * l.ExperiencePerExtraLevels
+	}
+	return total
+}
+
+// lastRewardLevel is the highest level that is not an extra level.
+func (l SeasonLevels) lastRewardLevel(season ActiveSeason) int {
+	if season.TotalRewards > l.StartingLevels {
+		return season.TotalRewards
+	}
+	return l.StartingLevels
+}
+
+// CalculateSeasonProgress works out the season level reached with xp.
+// When target is above zero the XP per day needed to reach it before the season ends at EndStamp is included.
+func CalculateSeasonProgress(levels SeasonLevels, season ActiveSeason, xp int64, target int, now time.Time) SeasonProgress {
+	output := SeasonProgress{
+		Season:     season,
+		Experience: xp,
+	}
+
+	remaining := xp
+	last := levels.lastRewardLevel(season)
+	for output.Level < last && remaining >= levels.LevelExperience(season, output.Level+1) {
+		remaining -= levels.LevelExperience(season, output.Level+1)
+		output.Level++
+	}
+	// Extra levels repeat forever, so they are counted in one go
+	if output.Level == last && levels.ExperiencePerExtraLevels > 0 {
+		extra := remaining / levels.ExperiencePerExtraLevels
+		output.Level += int(extra)
+		remaining -= extra * levels.ExperiencePerExtraLevels
+	}
+	output.LevelExperience = remaining
+	output.NextLevelExperience = levels.LevelExperience(season, output.Level+1)
+	if output.NextLevelExperience > 0 {
+		output.ExperienceToNextLevel = output.NextLevelExperience - remaining
+	}
+
+	output.RewardsEarned = output.Level
+	if output.RewardsEarned > season.TotalRewards {
+		output.RewardsEarned = season.TotalRewards
+	}
+	if output.Level > last {
+		output.ExtraLevels = output.Level - last
+	}
+	output.ExtraLevelCash = int64(output.ExtraLevels) * levels.ExtraLevelReward
+
+	if target > 0 {
+		goal := &SeasonTarget{
+			Level:      target,
+			Experience: levels.TotalExperience(season, target),
+		}
+		if goal.Experience > xp {
+			goal.ExperienceRemaining = goal.Experience - xp
+		}
+		if left := time.Unix(season.EndStamp, 0).Sub(now); left > 0 {
+			goal.DaysRemaining = left.Hours() / 24
+		}
+		if goal.ExperienceRemaining > 0 && goal.DaysRemaining > 0 {
+			// Round up, falling short by a fraction of an XP a day still misses the level
+			goal.ExperiencePerDay = int64(math.Ceil(float64(goal.ExperienceRemaining) / goal.DaysRemaining))
+		}
+		output.Target = goal
+	}
+
+	return output
+}
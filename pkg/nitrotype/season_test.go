@@ -0,0 +1,56 @@
+package nitrotype_test
+
+import (
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"testing"
+	"time"
+)
+
+func TestCalculateSeasonProgressTarget(t *testing.T) {
+	levels := nitrotype.SeasonLevels{
+		StartingLevels:                2,
+		ExperiencePerStartingLevel:    1000,
+		ExperiencePerAchievementLevel: 2000,
+		ExperiencePerExtraLevels:      5000,
+		ExtraLevelReward:              10000,
+	}
+	now := time.Unix(1640995200, 0)
+	season := nitrotype.ActiveSeason{
+		SeasonID:     3,
+		StartStamp:   now.Add(-10 * 24 * time.Hour).Unix(),
+		EndStamp:     now.Add(4 * 24 * time.Hour).Unix(),
+		TotalRewards: 5,
+	}
+	ended := season
+	ended.EndStamp = now.Add(-time.Hour).Unix()
+
+	tests := []struct {
+		name      string
+		season    nitrotype.ActiveSeason
+		xp        int64
+		target    int
+		level     int
+		remaining int64
+		perDay    int64
+	}{
+		{name: "fresh racer", season: season, xp: 0, target: 3, level: 0, remaining: 4000, perDay: 1000},
+		{name: "rounds up", season: season, xp: 1001, target: 3, level: 1, remaining: 2999, perDay: 750},
+		{name: "already reached", season: season, xp: 9000, target: 3, level: 5, remaining: 0, perDay: 0},
+		{name: "extra levels", season: season, xp: 18000, target: 7, level: 7, remaining: 0, perDay: 0},
+		{name: "season ended", season: ended, xp: 1000, target: 3, level: 1, remaining: 3000, perDay: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			progress := nitrotype.CalculateSeasonProgress(levels, test.season, test.xp, test.target, now)
+			if progress.Level != test.level {
+				t.Errorf("Level = %d, want %d", progress.Level, test.level)
+			}
+			if progress.Target == nil {
+				t.Fatal("Target = nil")
+			}
+			if progress.Target.ExperienceRemaining != test.remaining || progress.Target.ExperiencePerDay != test.perDay {
+				t.Errorf("Target = %+v, want %d remaining at %d a day", progress.Target, test.remaining, test.perDay)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package nitrotype
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// dealershipTimeLayouts are the formats tried for a dealership expiration that is not a unix time.
+var dealershipTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// ShopTimeline is the SHOP and DEALERSHIP sections of NTGLOBALS as seen at Now.
+type ShopTimeline struct {
+	Now         time.Time            `json:"now"`
+	Shop        []ShopRotation       `json:"shop"`
+	Dealerships []DealershipRotation `json:"dealerships"`
+}
+
+// ShopRotation is a shop section with its times parsed and items looked up.
+// RemainingSeconds counts down to ExpiresAt, StartsInSeconds to StartsAt for sections not open yet.
+type ShopRotation struct {
+	Category         string         `json:"category"`
+	ShopReleaseID    int            `json:"shopReleaseID"`
+	StartsAt         time.Time      `json:"startsAt"`
+	ExpiresAt        time.Time      `json:"expiresAt"`
+	Active           bool           `json:"active"`
+	StartsInSeconds  int64          `json:"startsInSeconds"`
+	RemainingSeconds int64          `json:"remainingSeconds"`
+	Items            []ShopTimeItem `json:"items"`
+}
+
+// DealershipRotation is a dealership with its expiration parsed and items looked up.
+// ExpiresAt is nil for dealerships that do not rotate, or when the expiration could not be read.
+type DealershipRotation struct {
+	DealershipID     int            `json:"dealershipID"`
+	AssetKey         string         `json:"assetKey"`
+	Name             string         `json:"name"`
+	ExpiresAt        *time.Time     `json:"expiresAt"`
+	Active           bool           `json:"active"`
+	RemainingSeconds int64          `json:"remainingSeconds"`
+	Items            []ShopTimeItem `json:"items"`
+}
+
+// ShopTimeItem is an item on sale with the car or loot it refers to, both are nil for other item types.
+type ShopTimeItem struct {
+	Type  string `json:"type"`
+	ID    int    `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Price *int64 `json:"price"`
+	Car   *Car   `json:"car,omitempty"`
+	Loot  *Loot  `json:"loot,omitempty"`
+}
+
+// ShopNextRotation is the next time the shop changes and which sections change then.
+type ShopNextRotation struct {
+	At        time.Time      `json:"at"`
+	InSeconds int64          `json:"inSeconds"`
+	Expiring  []ShopRotation `json:"expiring"`
+	Starting  []ShopRotation `json:"starting"`
+}
+
+// NewShopTimeline builds the shop timeline at now, sections are sorted by expiration.
+func NewShopTimeline(globals *NTGlobals, now time.Time) *ShopTimeline {
+	cars := make(map[int]*Car, len(globals.Cars))
+	for i := range globals.Cars {
+		cars[globals.Cars[i].CarID] = &globals.Cars[i]
+	}
+	loot := make(map[int]*Loot, len(globals.Loot))
+	for i := range globals.Loot {
+		loot[globals.Loot[i].LootID] = &globals.Loot[i]
+	}
+	resolve := func(itemType string, id int, price *int64) ShopTimeItem {
+		item := ShopTimeItem{Type: itemType, ID: id, Price: price}
+		switch itemType {
+		case "car":
+			if car, ok := cars[id]; ok {
+				item.Car = car
+				item.Name = car.Name
+			}
+		case "loot":
+			if entry, ok := loot[id]; ok {
+				item.Loot = entry
+				item.Name = entry.Name
+			}
+		}
+		return item
+	}
+
+	output := &ShopTimeline{
+		Now:         now,
+		Shop:        make([]ShopRotation, 0, len(globals.Shop)),
+		Dealerships: make([]DealershipRotation, 0, len(globals.Dealership)),
+	}
+	for _, shop := range globals.Shop {
+		rotation := ShopRotation{
+			Category:      shop.Category,
+			ShopReleaseID: shop.ShopReleaseID,
+			StartsAt:      time.Unix(shop.StartStamp, 0),
+			ExpiresAt:     time.Unix(shop.Expiration, 0),
+			Items:         make([]ShopTimeItem, 0, len(shop.Items)),
+		}
+		rotation.Active = !now.Before(rotation.StartsAt) && now.Before(rotation.ExpiresAt)
+		rotation.StartsInSeconds = secondsUntil(now, rotation.StartsAt)
+		rotation.RemainingSeconds = secondsUntil(now, rotation.ExpiresAt)
+		for _, item := range shop.Items {
+			rotation.Items = append(rotation.Items, resolve(item.Type, item.ID, item.Price))
+		}
+		output.Shop = append(output.Shop, rotation)
+	}
+	sort.SliceStable(output.Shop, func(i, j int) bool {
+		return output.Shop[i].ExpiresAt.Before(output.Shop[j].ExpiresAt)
+	})
+
+	for _, dealership := range globals.Dealership {
+		rotation := DealershipRotation{
+			DealershipID: dealership.DealershipID,
+			AssetKey:     dealership.AssetKey,
+			Name:         dealership.Name,
+			Active:       true,
+			Items:        make([]ShopTimeItem, 0, len(dealership.Items)),
+		}
+		if dealership.Expiration != nil {
+			if expiresAt, ok := parseDealershipTime(*dealership.Expiration); ok {
+				rotation.ExpiresAt = &expiresAt
+				rotation.Active = now.Before(expiresAt)
+				rotation.RemainingSeconds = secondsUntil(now, expiresAt)
+			}
+		}
+		for _, item := range dealership.Items {
+			rotation.Items = append(rotation.Items, resolve(item.Type, item.ID, item.Price))
+		}
+		output.Dealerships = append(output.Dealerships, rotation)
+	}
+
+	return output
+}
+
+// Current returns the timeline with only the shop sections and dealerships open at Now.
+func (t *ShopTimeline) Current() *ShopTimeline {
+	output := &ShopTimeline{
+		Now:         t.Now,
+		Shop:        []ShopRotation{},
+		Dealerships: []DealershipRotation{},
+	}
+	for _, rotation := range t.Shop {
+		if rotation.Active {
+			output.Shop = append(output.Shop, rotation)
+		}
+	}
+	for _, rotation := range t.Dealerships {
+		if rotation.Active {
+			output.Dealerships = append(output.Dealerships, rotation)
+		}
+	}
+	return output
+}
+
+// NextRotation returns the next time a shop section closes or opens after Now, or nil when none is scheduled.
+func (t *ShopTimeline) NextRotation() *ShopNextRotation {
+	var next time.Time
+	for _, rotation := range t.Shop {
+		for _, at := range []time.Time{rotation.StartsAt, rotation.ExpiresAt} {
+			if at.After(t.Now) && (next.IsZero() || at.Before(next)) {
+				next = at
+			}
+		}
+	}
+	if next.IsZero() {
+		return nil
+	}
+
+	output := &ShopNextRotation{
+		At:        next,
+		InSeconds: secondsUntil(t.Now, next),
+		Expiring:  []ShopRotation{},
+		Starting:  []ShopRotation{},
+	}
+	for _, rotation := range t.Shop {
+		if rotation.ExpiresAt.Equal(next) {
+			output.Expiring = append(output.Expiring, rotation)
+		}
+		if rotation.StartsAt.Equal(next) {
+			output.Starting = append(output.Starting, rotation)
+		}
+	}
+	return output
+}
+
+// parseDealershipTime reads a dealership expiration, which is a unix time or a date in a string.
+func parseDealershipTime(value string) (time.Time, bool) {
+	if stamp, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(stamp, 0), true
+	}
+	for _, layout := range dealershipTimeLayouts {
+		if output, err := time.Parse(layout, value); err == nil {
+			return output, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func secondsUntil(now time.Time, at time.Time) int64 {
+	if !at.After(now) {
+		return 0
+	}
+	return int64(at.Sub(now) / time.Second)
+}
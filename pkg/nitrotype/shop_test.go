@@ -0,0 +1,188 @@
+package nitrotype
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDealershipTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{value: "1641081600", want: time.Unix(1641081600, 0), ok: true},
+		{value: "2022-01-02T00:00:00Z", want: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), ok: true},
+		{value: "2022-01-02T01:00:00+01:00", want: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), ok: true},
+		{value: "2022-01-02 12:30:00", want: time.Date(2022, 1, 2, 12, 30, 0, 0, time.UTC), ok: true},
+		{value: "2022-01-02", want: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), ok: true},
+		{value: ""},
+		{value: "never"},
+		{value: "2022-13-02"},
+		{value: "02/01/2022"},
+		{value: "1641081600.5"},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, ok := parseDealershipTime(test.value)
+			if ok != test.ok || !got.Equal(test.want) {
+				t.Errorf("parseDealershipTime(%q) = %s, %v, want %s, %v", test.value, got, ok, test.want, test.ok)
+			}
+		})
+	}
+}
+
+func TestNewShopTimeline(t *testing.T) {
+	now := time.Unix(1641000000, 0)
+	stamp := func(offset time.Duration) int64 { return now.Add(offset).Unix() }
+	expiration := func(value string) *string { return &value }
+
+	globals := &NTGlobals{
+		Cars: []Car{{CarID: 5, Name: "Lambo"}},
+		Loot: []Loot{{LootID: 8, Name: "Trail"}},
+		Shop: []Shop{
+			{Category: "daily", ShopReleaseID: 1, StartStamp: stamp(-time.Hour), Expiration: stamp(2 * time.Hour), Items: []ShopItem{{Type: "car", ID: 5}, {Type: "loot", ID: 8}, {Type: "car", ID: 99}}},
+			{Category: "featured", ShopReleaseID: 2, StartStamp: stamp(-time.Hour), Expiration: stamp(time.Hour)},
+			{Category: "upcoming", ShopReleaseID: 3, StartStamp: stamp(time.Hour), Expiration: stamp(3 * time.Hour)},
+			{Category: "expired", ShopReleaseID: 4, StartStamp: stamp(-2 * time.Hour), Expiration: stamp(-time.Hour)},
+			{Category: "closing now", ShopReleaseID: 5, StartStamp: stamp(-time.Hour), Expiration: stamp(0)},
+			{Category: "opening now", ShopReleaseID: 6, StartStamp: stamp(0), Expiration: stamp(4 * time.Hour)},
+		},
+		Dealership: []Dealership{
+			{DealershipID: 1, Name: "permanent"},
+			{DealershipID: 2, Name: "rotating", Expiration: expiration("2022-01-01 05:20:00")},
+			{DealershipID: 3, Name: "gone", Expiration: expiration("1640990000")},
+			{DealershipID: 4, Name: "malformed", Expiration: expiration("soon")},
+		},
+	}
+
+	timeline := NewShopTimeline(globals, now)
+
+	tests := []struct {
+		category  string
+		active    bool
+		startsIn  int64
+		remaining int64
+	}{
+		{category: "expired", active: false, startsIn: 0, remaining: 0},
+		{category: "closing now", active: false, startsIn: 0, remaining: 0},
+		{category: "featured", active: true, startsIn: 0, remaining: 3600},
+		{category: "daily", active: true, startsIn: 0, remaining: 7200},
+		{category: "upcoming", active: false, startsIn: 3600, remaining: 10800},
+		{category: "opening now", active: true, startsIn: 0, remaining: 14400},
+	}
+	if len(timeline.Shop) != len(tests) {
+		t.Fatalf("Shop = %+v", timeline.Shop)
+	}
+	for i, test := range tests {
+		got := timeline.Shop[i]
+		if got.Category != test.category || got.Active != test.active || got.StartsInSeconds != test.startsIn || got.RemainingSeconds != test.remaining {
+			t.Errorf("Shop[%d] = %s active %v starts in %d remaining %d, want %+v", i, got.Category, got.Active, got.StartsInSeconds, got.RemainingSeconds, test)
+		}
+	}
+
+	daily := timeline.Shop[3].Items
+	if len(daily) != 3 || daily[0].Car == nil || daily[0].Name != "Lambo" || daily[1].Loot == nil || daily[1].Name != "Trail" || daily[2].Car != nil || daily[2].Name != "" {
+		t.Errorf("daily items = %+v", daily)
+	}
+
+	dealerships := []struct {
+		name      string
+		expires   bool
+		active    bool
+		remaining int64
+	}{
+		{name: "permanent", expires: false, active: true},
+		{name: "rotating", expires: true, active: true, remaining: 14400},
+		{name: "gone", expires: true, active: false},
+		{name: "malformed", expires: false, active: true},
+	}
+	for i, test := range dealerships {
+		got := timeline.Dealerships[i]
+		if got.Name != test.name || (got.ExpiresAt != nil) != test.expires || got.Active != test.active || got.RemainingSeconds != test.remaining {
+			t.Errorf("Dealerships[%d] = %+v, want %+v", i, got, test)
+		}
+	}
+
+	current := timeline.Current()
+	if len(current.Shop) != 3 || len(current.Dealerships) != 3 {
+		t.Errorf("Current() = %d shop sections and %d dealerships, want 3 and 3", len(current.Shop), len(current.Dealerships))
+	}
+}
+
+func TestShopTimelineNextRotation(t *testing.T) {
+	now := time.Unix(1641000000, 0)
+	rotation := func(category string, start time.Duration, end time.Duration) Shop {
+		return Shop{Category: category, StartStamp: now.Add(start).Unix(), Expiration: now.Add(end).Unix()}
+	}
+
+	tests := []struct {
+		name     string
+		shop     []Shop
+		in       int64
+		expiring []string
+		starting []string
+	}{
+		{
+			name:     "next expiration",
+			shop:     []Shop{rotation("daily", -time.Hour, 2*time.Hour), rotation("featured", -time.Hour, time.Hour)},
+			in:       3600,
+			expiring: []string{"featured"},
+			starting: []string{},
+		},
+		{
+			name:     "expiring and starting together",
+			shop:     []Shop{rotation("daily", -time.Hour, time.Hour), rotation("tomorrow", time.Hour, 25*time.Hour)},
+			in:       3600,
+			expiring: []string{"daily"},
+			starting: []string{"tomorrow"},
+		},
+		{
+			// Changes happening at now already happened, the next one is later
+			name:     "rotation at now",
+			shop:     []Shop{rotation("daily", -time.Hour, 0), rotation("tomorrow", 0, 24*time.Hour)},
+			in:       86400,
+			expiring: []string{"tomorrow"},
+			starting: []string{},
+		},
+		{
+			name: "everything expired",
+			shop: []Shop{rotation("daily", -2*time.Hour, -time.Hour)},
+		},
+		{
+			name: "empty schedule",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next := NewShopTimeline(&NTGlobals{Shop: test.shop}, now).NextRotation()
+			if test.expiring == nil {
+				if next != nil {
+					t.Errorf("NextRotation() = %+v, want nil", next)
+				}
+				return
+			}
+			if next == nil {
+				t.Fatal("NextRotation() = nil")
+			}
+			if next.InSeconds != test.in || !next.At.Equal(now.Add(time.Duration(test.in)*time.Second)) {
+				t.Errorf("NextRotation() at %s in %d, want in %d", next.At, next.InSeconds, test.in)
+			}
+			if got := shopCategories(next.Expiring); !reflect.DeepEqual(got, test.expiring) {
+				t.Errorf("Expiring = %q, want %q", got, test.expiring)
+			}
+			if got := shopCategories(next.Starting); !reflect.DeepEqual(got, test.starting) {
+				t.Errorf("Starting = %q, want %q", got, test.starting)
+			}
+		})
+	}
+}
+
+func shopCategories(rotations []ShopRotation) []string {
+	output := make([]string, 0, len(rotations))
+	for _, rotation := range rotations {
+		output = append(output, rotation.Category)
+	}
+	return output
+}
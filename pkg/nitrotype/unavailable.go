@@ -0,0 +1,155 @@
+package nitrotype
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// PageKind is the kind of page Nitro Type served instead of the requested content.
+type PageKind string
+
+const (
+	PageChallenge   PageKind = "challenge"
+	PageMaintenance PageKind = "maintenance"
+	PageError       PageKind = "error"
+)
+
+var (
+	// challengeMarkers are the titles and forms of Cloudflare and captcha interstitials, only trusted on a 403 or 503.
+	// Normal pages also load Cloudflare's challenge scripts, so script paths are not markers.
+	challengeMarkers = [][]byte{
+		[]byte("<title>Just a moment...</title>"),
+		[]byte("Attention Required! | Cloudflare"),
+		[]byte(`id="challenge-form"`),
+		[]byte(`id="cf-challenge-running"`),
+		[]byte("cf-browser-verification"),
+		[]byte("Checking your browser before accessing"),
+	}
+
+	// maintenanceTitleMarkers are lower case page titles of planned downtime pages.
+	maintenanceTitleMarkers = [][]byte{
+		[]byte("<title>maintenance"),
+		[]byte("<title>down for maintenance"),
+		[]byte("<title>nitro type is down"),
+	}
+
+	// maintenanceMarkers are lower case phrases of planned downtime pages, only trusted on error statuses.
+	maintenanceMarkers = [][]byte{
+		[]byte("down for maintenance"),
+		[]byte("under maintenance"),
+		[]byte("scheduled maintenance"),
+		[]byte("maintenance mode"),
+	}
+
+	// errorMarkers are lower case parts of Cloudflare origin error pages, which may be served with a 200.
+	// Their titles look like "nitrotype.com | 502: Bad gateway".
+	errorMarkers = [][]byte{
+		[]byte("cf-error-details"),
+		[]byte("502: bad gateway"),
+		[]byte("504: gateway time-out"),
+		[]byte("521: web server is down"),
+		[]byte("522: connection timed out"),
+	}
+)
+
+// UnavailableError is returned when Nitro Type served a challenge, maintenance or error page.
+// It matches ErrBotChallenge, ErrMaintenance or ErrUpstreamError with errors.Is.
+type UnavailableError struct {
+	Kind       PageKind
+	StatusCode int
+	URL        string
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("nitro type served a %s page (status %d) for %s", e.Kind, e.StatusCode, e.URL)
+}
+
+func (e *UnavailableError) Is(target error) bool {
+	switch e.Kind {
+	case PageChallenge:
+		return target == ErrBotChallenge
+	case PageMaintenance:
+		return target == ErrMaintenance
+	}
+	return target == ErrUpstreamError
+}
+
+// DetectPage recognises challenge, maintenance and error pages from the response.
+// Only HTML documents are inspected so script contents cannot trigger a false match.
+func DetectPage(statusCode int, header http.Header, body []byte) (PageKind, bool) {
+	if header.Get("Cf-Mitigated") == "challenge" {
+		return PageChallenge, true
+	}
+
+	isHTML := bytes.HasPrefix(bytes.TrimSpace(body), []byte("<"))
+	if isHTML {
+		lower := bytes.ToLower(body)
+		isBlocked := statusCode == http.StatusForbidden || statusCode == http.StatusServiceUnavailable
+		if isBlocked && containsAny(body, challengeMarkers) {
+			return PageChallenge, true
+		}
+		if containsAny(lower, maintenanceTitleMarkers) {
+			return PageMaintenance, true
+		}
+		if statusCode != http.StatusOK && containsAny(lower, maintenanceMarkers) {
+			return PageMaintenance, true
+		}
+		if containsAny(lower, errorMarkers) {
+			return PageError, true
+		}
+	}
+
+	switch {
+	case statusCode == http.StatusServiceUnavailable && header.Get("Retry-After") != "":
+		return PageMaintenance, true
+	case statusCode >= 500:
+		return PageError, true
+	}
+	return "", false
+}
+
+func containsAny(body []byte, markers [][]byte) bool {
+	for _, marker := range markers {
+		if bytes.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+var pageCounts = struct {
+	sync.Mutex
+	counts map[PageKind]int64
+}{counts: map[PageKind]int64{}}
+
+// UnavailablePageCounts returns how many of each unavailable page kind have been seen since start.
+func UnavailablePageCounts() map[PageKind]int64 {
+	pageCounts.Lock()
+	defer pageCounts.Unlock()
+
+	output := map[PageKind]int64{
+		PageChallenge:   0,
+		PageMaintenance: 0,
+		PageError:       0,
+	}
+	for kind, count := range pageCounts.counts {
+		output[kind] = count
+	}
+	return output
+}
+
+// checkPage returns an UnavailableError and records it when the response is not real content.
+func checkPage(statusCode int, header http.Header, body []byte, url string) error {
+	kind, ok := DetectPage(statusCode, header, body)
+	if !ok {
+		return nil
+	}
+
+	pageCounts.Lock()
+	pageCounts.counts[kind]++
+	pageCounts.Unlock()
+
+	return &UnavailableError{Kind: kind, StatusCode: statusCode, URL: url}
+}
@@ -0,0 +1,131 @@
+package nitrotype_test
+
+import (
+	"net/http"
+	"nt-bootstrap-scraper/pkg/nitrotype"
+	"testing"
+)
+
+// cloudflareScript is the JS detection snippet Cloudflare injects into normal pages.
+const cloudflareScript = `<script>(function(){window._cf_chl_opt={cvId:'3',cZone:'www.nitrotype.com'};` +
+	`var a=document.createElement('script');a.src='/cdn-cgi/challenge-platform/h/b/orchestrate/jsch/v1?ray=1';` +
+	`document.getElementsByTagName('head')[0].appendChild(a);})();</script>`
+
+func TestDetectPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		header     http.Header
+		body       string
+		kind       nitrotype.PageKind
+		ok         bool
+	}{
+		{
+			name:       "normal page",
+			statusCode: http.StatusOK,
+			body:       `<!DOCTYPE html><html><head><title>Nitro Type</title></head><body><div id="root"></div></body></html>`,
+		},
+		{
+			name:       "normal page with challenge script",
+			statusCode: http.StatusOK,
+			body:       `<!DOCTYPE html><html><head><title>Nitro Type</title></head><body><div id="root"></div>` + cloudflareScript + `</body></html>`,
+		},
+		{
+			name:       "challenge title on 200",
+			statusCode: http.StatusOK,
+			body:       `<!DOCTYPE html><html><head><title>Just a moment...</title></head></html>`,
+		},
+		{
+			name:       "challenge interstitial",
+			statusCode: http.StatusForbidden,
+			body:       `<!DOCTYPE html><html><head><title>Just a moment...</title></head><body>` + cloudflareScript + `</body></html>`,
+			kind:       nitrotype.PageChallenge,
+			ok:         true,
+		},
+		{
+			name:       "challenge form on 503",
+			statusCode: http.StatusServiceUnavailable,
+			body:       `<!DOCTYPE html><html><body><form id="challenge-form" action="/?__cf_chl_f_tk=abc"></form></body></html>`,
+			kind:       nitrotype.PageChallenge,
+			ok:         true,
+		},
+		{
+			name:       "forbidden without challenge",
+			statusCode: http.StatusForbidden,
+			body:       `<!DOCTYPE html><html><head><title>Forbidden</title></head><body>` + cloudflareScript + `</body></html>`,
+		},
+		{
+			name:       "mitigated header",
+			statusCode: http.StatusOK,
+			header:     http.Header{"Cf-Mitigated": {"challenge"}},
+			body:       `<!DOCTYPE html><html></html>`,
+			kind:       nitrotype.PageChallenge,
+			ok:         true,
+		},
+		{
+			name:       "maintenance title",
+			statusCode: http.StatusOK,
+			body:       `<!DOCTYPE html><html><head><title>Maintenance</title></head></html>`,
+			kind:       nitrotype.PageMaintenance,
+			ok:         true,
+		},
+		{
+			name:       "maintenance phrase on 200",
+			statusCode: http.StatusOK,
+			body:       `<!DOCTYPE html><html><body>The garage is under maintenance this weekend.</body></html>`,
+		},
+		{
+			name:       "maintenance phrase on 503",
+			statusCode: http.StatusServiceUnavailable,
+			body:       `<!DOCTYPE html><html><body>Nitro Type is down for maintenance.</body></html>`,
+			kind:       nitrotype.PageMaintenance,
+			ok:         true,
+		},
+		{
+			name:       "retry after",
+			statusCode: http.StatusServiceUnavailable,
+			header:     http.Header{"Retry-After": {"3600"}},
+			body:       `{}`,
+			kind:       nitrotype.PageMaintenance,
+			ok:         true,
+		},
+		{
+			name:       "cloudflare bad gateway on 200",
+			statusCode: http.StatusOK,
+			body:       `<!DOCTYPE html><html><head><title>nitrotype.com | 502: Bad gateway</title></head></html>`,
+			kind:       nitrotype.PageError,
+			ok:         true,
+		},
+		{
+			name:       "cloudflare web server down",
+			statusCode: 521,
+			body:       `<!DOCTYPE html><html><head><title>nitrotype.com | 521: Web server is down</title></head></html>`,
+			kind:       nitrotype.PageError,
+			ok:         true,
+		},
+		{
+			name:       "server error status",
+			statusCode: http.StatusInternalServerError,
+			body:       `oops`,
+			kind:       nitrotype.PageError,
+			ok:         true,
+		},
+		{
+			name:       "script mentioning maintenance",
+			statusCode: http.StatusOK,
+			body:       `window.NTGLOBALS={"PAGE_LABELS":{"x":"<title>Maintenance"}};`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := test.header
+			if header == nil {
+				header = http.Header{}
+			}
+			kind, ok := nitrotype.DetectPage(test.statusCode, header, []byte(test.body))
+			if kind != test.kind || ok != test.ok {
+				t.Errorf("DetectPage() = %q, %v, want %q, %v", kind, ok, test.kind, test.ok)
+			}
+		})
+	}
+}
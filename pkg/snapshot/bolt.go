@@ -0,0 +1,137 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	snapshotsBucket = []byte("snapshots")
+	payloadsBucket  = []byte("payloads")
+)
+
+// BoltStore keeps snapshots in a single BoltDB file.
+// Snapshots are keyed by ID so the bucket cursor walks them in time order, payloads are keyed by hash.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens or creates a BoltDB snapshot store at path.
+func OpenBolt(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open snapshot database: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{snapshotsBucket, payloadsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to create snapshot buckets: %w", err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Put(timestamp time.Time, data []byte) (Snapshot, bool, error) {
+	snapshot := newSnapshot(timestamp, data)
+	created := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		snapshots := tx.Bucket(snapshotsBucket)
+		if _, value := snapshots.Cursor().Last(); value != nil {
+			var latest Snapshot
+			if err := json.Unmarshal(value, &latest); err != nil {
+				return err
+			}
+			if latest.Hash == snapshot.Hash {
+				snapshot = latest
+				return nil
+			}
+		}
+
+		payloads := tx.Bucket(payloadsBucket)
+		if payloads.Get([]byte(snapshot.Hash)) == nil {
+			if err := payloads.Put([]byte(snapshot.Hash), data); err != nil {
+				return err
+			}
+		}
+		value, err := json.Marshal(snapshot)
+		if err != nil {
+			return err
+		}
+		created = true
+		return snapshots.Put([]byte(snapshot.ID), value)
+	})
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("unable to save snapshot: %w", err)
+	}
+	return snapshot, created, nil
+}
+
+func (s *BoltStore) List() ([]Snapshot, error) {
+	output := []Snapshot{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(snapshotsBucket).ForEach(func(_, value []byte) error {
+			var snapshot Snapshot
+			if err := json.Unmarshal(value, &snapshot); err != nil {
+				return err
+			}
+			output = append(output, snapshot)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list snapshots: %w", err)
+	}
+	return output, nil
+}
+
+func (s *BoltStore) Get(id string) (Snapshot, []byte, error) {
+	return s.read(func(snapshots *bolt.Bucket) []byte {
+		return snapshots.Get([]byte(id))
+	})
+}
+
+func (s *BoltStore) Latest() (Snapshot, []byte, error) {
+	return s.read(func(snapshots *bolt.Bucket) []byte {
+		_, value := snapshots.Cursor().Last()
+		return value
+	})
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// read loads the snapshot find picks out of the snapshots bucket along with its payload.
+func (s *BoltStore) read(find func(snapshots *bolt.Bucket) []byte) (Snapshot, []byte, error) {
+	var snapshot Snapshot
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := find(tx.Bucket(snapshotsBucket))
+		if value == nil {
+			return ErrNotFound
+		}
+		if err := json.Unmarshal(value, &snapshot); err != nil {
+			return err
+		}
+		payload := tx.Bucket(payloadsBucket).Get([]byte(snapshot.Hash))
+		if payload == nil {
+			return fmt.Errorf("payload %s of snapshot %s is missing", snapshot.Hash, snapshot.ID)
+		}
+		// Bolt values are only valid during the transaction
+		data = append([]byte(nil), payload...)
+		return nil
+	})
+	if err != nil {
+		return Snapshot{}, nil, fmt.Errorf("unable to read snapshot: %w", err)
+	}
+	return snapshot, data, nil
+}
@@ -0,0 +1,176 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DirStore keeps snapshots as plain files, so they can be browsed and copied with ordinary tools.
+// The directory holds snapshots/{id}.json describing each snapshot and payloads/{hash}.json with the data.
+type DirStore struct {
+	dir string
+
+	// mu serialises writes so the latest snapshot cannot change during a Put
+	mu sync.Mutex
+}
+
+// OpenDir opens or creates a directory snapshot store.
+func OpenDir(dir string) (*DirStore, error) {
+	for _, name := range []string{"snapshots", "payloads"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			return nil, fmt.Errorf("unable to create snapshot directory: %w", err)
+		}
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+func (s *DirStore) Put(timestamp time.Time, data []byte) (Snapshot, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := newSnapshot(timestamp, data)
+	latest, err := s.latest()
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Snapshot{}, false, fmt.Errorf("unable to save snapshot: %w", err)
+	}
+	if err == nil && latest.Hash == snapshot.Hash {
+		return latest, false, nil
+	}
+
+	payloadPath := s.payloadPath(snapshot.Hash)
+	if _, err := os.Stat(payloadPath); os.IsNotExist(err) {
+		if err := writeFile(payloadPath, data); err != nil {
+			return Snapshot{}, false, fmt.Errorf("unable to save snapshot: %w", err)
+		}
+	}
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("unable to save snapshot: %w", err)
+	}
+	if err := writeFile(s.snapshotPath(snapshot.ID), value); err != nil {
+		return Snapshot{}, false, fmt.Errorf("unable to save snapshot: %w", err)
+	}
+	return snapshot, true, nil
+}
+
+func (s *DirStore) List() ([]Snapshot, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list snapshots: %w", err)
+	}
+	output := make([]Snapshot, 0, len(ids))
+	for _, id := range ids {
+		snapshot, err := s.snapshot(id)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list snapshots: %w", err)
+		}
+		output = append(output, snapshot)
+	}
+	return output, nil
+}
+
+func (s *DirStore) Get(id string) (Snapshot, []byte, error) {
+	if !validID(id) {
+		return Snapshot{}, nil, fmt.Errorf("unable to read snapshot: %w", ErrNotFound)
+	}
+	snapshot, err := s.snapshot(id)
+	if err != nil {
+		return Snapshot{}, nil, fmt.Errorf("unable to read snapshot: %w", err)
+	}
+	return s.payload(snapshot)
+}
+
+func (s *DirStore) Latest() (Snapshot, []byte, error) {
+	snapshot, err := s.latest()
+	if err != nil {
+		return Snapshot{}, nil, fmt.Errorf("unable to read snapshot: %w", err)
+	}
+	return s.payload(snapshot)
+}
+
+func (s *DirStore) Close() error {
+	return nil
+}
+
+func (s *DirStore) latest() (Snapshot, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if len(ids) == 0 {
+		return Snapshot{}, ErrNotFound
+	}
+	return s.snapshot(ids[len(ids)-1])
+}
+
+// ids lists the snapshot IDs in time order, which is also name order.
+func (s *DirStore) ids() ([]string, error) {
+	entries, err := ioutil.ReadDir(filepath.Join(s.dir, "snapshots"))
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !validID(id) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s *DirStore) snapshot(id string) (Snapshot, error) {
+	value, err := ioutil.ReadFile(s.snapshotPath(id))
+	if os.IsNotExist(err) {
+		return Snapshot{}, ErrNotFound
+	}
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var snapshot Snapshot
+	err = json.Unmarshal(value, &snapshot)
+	return snapshot, err
+}
+
+func (s *DirStore) payload(snapshot Snapshot) (Snapshot, []byte, error) {
+	data, err := ioutil.ReadFile(s.payloadPath(snapshot.Hash))
+	if err != nil {
+		return Snapshot{}, nil, fmt.Errorf("unable to read payload of snapshot %s: %w", snapshot.ID, err)
+	}
+	return snapshot, data, nil
+}
+
+func (s *DirStore) snapshotPath(id string) string {
+	return filepath.Join(s.dir, "snapshots", id+".json")
+}
+
+func (s *DirStore) payloadPath(hash string) string {
+	return filepath.Join(s.dir, "payloads", hash+".json")
+}
+
+// writeFile writes through a temporary file so readers never see half a file.
+func writeFile(path string, data []byte) error {
+	file, err := ioutil.TempFile(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,76 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// idLayout formats snapshot IDs so they sort in the order they were taken.
+const idLayout = "20060102T150405.000000000Z"
+
+var (
+	ErrNotFound     = fmt.Errorf("snapshot not found")
+	ErrUnknownStore = fmt.Errorf("unknown snapshot store")
+)
+
+// Snapshot describes a stored payload, the payload itself is read with Store.Get.
+type Snapshot struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Hash      string    `json:"hash"`
+	Size      int       `json:"size"`
+}
+
+// Store keeps payloads over time, such as every bootstrap file scraped.
+// Payloads are stored once per content hash, and a payload identical to the latest snapshot is not recorded again.
+type Store interface {
+	// Put records data taken at timestamp. created is false when it matched the latest snapshot,
+	// in which case that snapshot is returned.
+	Put(timestamp time.Time, data []byte) (snapshot Snapshot, created bool, err error)
+
+	// List returns every snapshot, oldest first.
+	List() ([]Snapshot, error)
+
+	// Get returns a snapshot and its payload by ID, or ErrNotFound.
+	Get(id string) (Snapshot, []byte, error)
+
+	// Latest returns the newest snapshot and its payload, or ErrNotFound when the store is empty.
+	Latest() (Snapshot, []byte, error)
+
+	Close() error
+}
+
+// Open opens the store described by spec, either bolt:{file} or dir:{directory}.
+func Open(spec string) (Store, error) {
+	parts := strings.SplitN(spec, ":", 2)
+	if len(parts) == 2 && parts[1] != "" {
+		switch parts[0] {
+		case "bolt":
+			return OpenBolt(parts[1])
+		case "dir":
+			return OpenDir(parts[1])
+		}
+	}
+	return nil, fmt.Errorf("%w: %q (expected bolt:{file} or dir:{directory})", ErrUnknownStore, spec)
+}
+
+// newSnapshot describes data taken at timestamp.
+func newSnapshot(timestamp time.Time, data []byte) Snapshot {
+	hash := sha256.Sum256(data)
+	timestamp = timestamp.UTC()
+	return Snapshot{
+		ID:        timestamp.Format(idLayout),
+		Timestamp: timestamp,
+		Hash:      hex.EncodeToString(hash[:]),
+		Size:      len(data),
+	}
+}
+
+// validID reports whether id could have been made by newSnapshot, so it is safe to use as a file name.
+func validID(id string) bool {
+	_, err := time.Parse(idLayout, id)
+	return err == nil
+}
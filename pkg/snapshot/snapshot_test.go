@@ -0,0 +1,161 @@
+package snapshot_test
+
+import (
+	"errors"
+	"nt-bootstrap-scraper/pkg/snapshot"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// forEachStore runs a test against a new, empty store of every kind.
+func forEachStore(t *testing.T, test func(t *testing.T, store snapshot.Store)) {
+	stores := []struct {
+		name string
+		open func(dir string) (snapshot.Store, error)
+	}{
+		{name: "bolt", open: func(dir string) (snapshot.Store, error) { return snapshot.OpenBolt(filepath.Join(dir, "snapshots.db")) }},
+		{name: "dir", open: func(dir string) (snapshot.Store, error) { return snapshot.OpenDir(dir) }},
+	}
+	for _, s := range stores {
+		t.Run(s.name, func(t *testing.T) {
+			store, err := s.open(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+			test(t, store)
+		})
+	}
+}
+
+func TestStorePut(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store snapshot.Store) {
+		start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+		puts := []struct {
+			data    string
+			created bool
+		}{
+			{data: `{"a":1}`, created: true},
+			// Identical to the latest snapshot
+			{data: `{"a":1}`, created: false},
+			{data: `{"a":2}`, created: true},
+			// Identical to an older snapshot only, so it is recorded again
+			{data: `{"a":1}`, created: true},
+		}
+		var saved []snapshot.Snapshot
+		for i, put := range puts {
+			got, created, err := store.Put(start.Add(time.Duration(i)*time.Minute), []byte(put.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created != put.created {
+				t.Errorf("Put %d created = %v, want %v", i, created, put.created)
+			}
+			saved = append(saved, got)
+		}
+		if saved[1] != saved[0] {
+			t.Errorf("duplicate Put = %+v, want the latest snapshot %+v", saved[1], saved[0])
+		}
+		if saved[0].Hash != saved[3].Hash || saved[0].Hash == saved[2].Hash || saved[0].Size != 7 {
+			t.Errorf("snapshots = %+v", saved)
+		}
+
+		list, err := store.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 3 || list[0] != saved[0] || list[1] != saved[2] || list[2] != saved[3] {
+			t.Errorf("List() = %+v, want %+v", list, []snapshot.Snapshot{saved[0], saved[2], saved[3]})
+		}
+	})
+}
+
+func TestStoreOrder(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store snapshot.Store) {
+		if _, _, err := store.Latest(); !errors.Is(err, snapshot.ErrNotFound) {
+			t.Errorf("Latest() of an empty store error = %v, want ErrNotFound", err)
+		}
+		if list, err := store.List(); err != nil || len(list) != 0 {
+			t.Errorf("List() of an empty store = %+v, %v", list, err)
+		}
+
+		// Taken out of order, and in a zone other than UTC
+		zone := time.FixedZone("UTC+2", 2*60*60)
+		base := time.Date(2022, 1, 1, 12, 0, 0, 0, zone)
+		for _, put := range []struct {
+			offset time.Duration
+			data   string
+		}{
+			{offset: time.Hour, data: "second"},
+			{offset: 0, data: "first"},
+			{offset: 2*time.Hour + time.Millisecond, data: "third"},
+		} {
+			if _, _, err := store.Put(base.Add(put.offset), []byte(put.data)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		list, err := store.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"first", "second", "third"}
+		if len(list) != len(want) {
+			t.Fatalf("List() = %+v", list)
+		}
+		for i, item := range list {
+			if i > 0 && !list[i-1].Timestamp.Before(item.Timestamp) {
+				t.Errorf("List() is not oldest first: %+v", list)
+			}
+			got, data, err := store.Get(item.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != item || string(data) != want[i] {
+				t.Errorf("Get(%s) = %+v %q, want %+v %q", item.ID, got, data, item, want[i])
+			}
+		}
+		if list[0].ID != "20220101T100000.000000000Z" || list[0].Timestamp.Location() != time.UTC {
+			t.Errorf("first snapshot = %+v, want an ID in UTC", list[0])
+		}
+
+		latest, data, err := store.Latest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if latest != list[2] || string(data) != "third" {
+			t.Errorf("Latest() = %+v %q, want %+v", latest, data, list[2])
+		}
+	})
+}
+
+func TestStoreGetMissing(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store snapshot.Store) {
+		if _, _, err := store.Put(time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC), []byte("data")); err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range []string{"20220101T130000.000000000Z", "", "../payloads/x", "latest"} {
+			if _, _, err := store.Get(id); !errors.Is(err, snapshot.ErrNotFound) {
+				t.Errorf("Get(%q) error = %v, want ErrNotFound", id, err)
+			}
+		}
+	})
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	for _, spec := range []string{"bolt:" + filepath.Join(dir, "snapshots.db"), "dir:" + filepath.Join(dir, "snapshots")} {
+		store, err := snapshot.Open(spec)
+		if err != nil {
+			t.Errorf("Open(%q) error = %v", spec, err)
+			continue
+		}
+		store.Close()
+	}
+	for _, spec := range []string{"", "bolt:", "s3:bucket", "snapshots"} {
+		if _, err := snapshot.Open(spec); !errors.Is(err, snapshot.ErrUnknownStore) {
+			t.Errorf("Open(%q) error = %v, want ErrUnknownStore", spec, err)
+		}
+	}
+}